@@ -0,0 +1,264 @@
+package gospell
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"sort"
+	"unicode/utf8"
+)
+
+// The binary format written by Trie.WriteTo is the magic string, a version
+// byte, the number of nodes as a uvarint, the root node and a CRC-32 of
+// everything before it. Each node is a flags byte, its weight as a varint if
+// it is a leaf, its number of children as a uvarint, and then each child's
+// rune as a uvarint followed by the child node, in rune order.
+const (
+	binaryMagic   = "gospell"
+	binaryVersion = 1
+
+	leafFlag = 1 << 0
+
+	// Deeper than any real word; guards against corrupt input
+	maxBinaryDepth = 1 << 12
+)
+
+var (
+	// The data doesn't start with the gospell magic string
+	ErrNotDictionary = errors.New("not a gospell dictionary")
+	// The data was written by a newer, incompatible version of gospell
+	ErrVersion = errors.New("unsupported dictionary version")
+	// The data is truncated or damaged
+	ErrCorrupt = errors.New("corrupt dictionary")
+)
+
+// Write the Trie to w in gospell's binary format, returning the number of
+// bytes written. Implements io.WriterTo.
+func (t *Trie) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: bufio.NewWriter(w), crc: crc32.NewIEEE()}
+	e.write([]byte(binaryMagic))
+	e.write([]byte{binaryVersion})
+	e.uvarint(uint64(t.nodeCount()))
+	e.node(t)
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], e.crc.Sum32())
+	e.write(sum[:])
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	return e.n, e.err
+}
+
+// Replace the contents of the Trie with a Trie read from r in the format
+// written by WriteTo, returning the number of bytes read. Implements
+// io.ReaderFrom, so r is read to EOF. Bad input is reported with
+// ErrNotDictionary, ErrVersion or ErrCorrupt, and leaves the Trie unchanged.
+func (t *Trie) ReadFrom(r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(data)), err
+	}
+	return int64(len(data)), t.UnmarshalBinary(data)
+}
+
+// Implements encoding.BinaryMarshaler. See Trie.WriteTo.
+func (t *Trie) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := t.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Implements encoding.BinaryUnmarshaler. See Trie.ReadFrom.
+func (t *Trie) UnmarshalBinary(data []byte) error {
+	header := len(binaryMagic) + 1
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) {
+		return ErrNotDictionary
+	}
+	if len(data) < header+4 {
+		return fmt.Errorf("%w: unexpected end of data", ErrCorrupt)
+	}
+	if v := data[len(binaryMagic)]; v != binaryVersion {
+		return fmt.Errorf("%w %d, expected %d", ErrVersion, v, binaryVersion)
+	}
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[len(body):]) {
+		return fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+
+	d := &decoder{data: body, pos: header}
+	count, err := d.uvarint()
+	if err != nil {
+		return err
+	}
+	// Every node takes at least two bytes
+	if count == 0 || count > uint64(len(body)) {
+		return fmt.Errorf("%w: %d nodes", ErrCorrupt, count)
+	}
+	d.nodes = make([]Trie, count)
+	root, err := d.node(0)
+	if err != nil {
+		return err
+	}
+	if len(d.nodes) != 0 {
+		return fmt.Errorf("%w: %d missing nodes", ErrCorrupt, len(d.nodes))
+	}
+	if d.pos != len(body) {
+		return fmt.Errorf("%w: %d bytes of trailing data", ErrCorrupt, len(body)-d.pos)
+	}
+	*t = *root
+	return nil
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	crc hash.Hash32
+	n   int64
+	err error
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (e *encoder) write(b []byte) {
+	if e.err != nil {
+		return
+	}
+	e.crc.Write(b)
+	n, err := e.w.Write(b)
+	e.n += int64(n)
+	e.err = err
+}
+
+func (e *encoder) uvarint(x uint64) {
+	e.write(e.buf[:binary.PutUvarint(e.buf[:], x)])
+}
+
+// Count the nodes in the Trie, including t
+func (t *Trie) nodeCount() int {
+	n := 1
+	for _, child := range t.children {
+		if child != nil {
+			n += child.nodeCount()
+		}
+	}
+	return n
+}
+
+func (e *encoder) node(t *Trie) {
+	keys := make([]rune, 0, len(t.children))
+	for r, child := range t.children {
+		if child != nil {
+			keys = append(keys, r)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	if t.leaf {
+		e.write([]byte{leafFlag})
+		e.write(e.buf[:binary.PutVarint(e.buf[:], int64(t.weight))])
+	} else {
+		e.write([]byte{0})
+	}
+	e.uvarint(uint64(len(keys)))
+	for _, r := range keys {
+		e.uvarint(uint64(r))
+		e.node(t.children[r])
+	}
+}
+
+type decoder struct {
+	data  []byte
+	pos   int
+	nodes []Trie
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, fmt.Errorf("%w: unexpected end of data", ErrCorrupt)
+	}
+	d.pos++
+	return d.data[d.pos-1], nil
+}
+
+func (d *decoder) uvarint() (uint64, error) {
+	x, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: bad varint", ErrCorrupt)
+	}
+	d.pos += n
+	return x, nil
+}
+
+func (d *decoder) varint() (int64, error) {
+	x, n := binary.Varint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: bad varint", ErrCorrupt)
+	}
+	d.pos += n
+	return x, nil
+}
+
+func (d *decoder) node(depth int) (*Trie, error) {
+	if depth > maxBinaryDepth {
+		return nil, fmt.Errorf("%w: too deep", ErrCorrupt)
+	}
+	if len(d.nodes) == 0 {
+		return nil, fmt.Errorf("%w: too many nodes", ErrCorrupt)
+	}
+	t := &d.nodes[0]
+	d.nodes = d.nodes[1:]
+
+	flags, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	if flags&^leafFlag != 0 {
+		return nil, fmt.Errorf("%w: unknown node flags %#x", ErrCorrupt, flags)
+	}
+	if flags&leafFlag != 0 {
+		weight, err := d.varint()
+		if err != nil {
+			return nil, err
+		}
+		t.leaf = true
+		t.weight = int(weight)
+		t.heaviest = t.weight
+	} else {
+		t.heaviest = -infinity
+	}
+
+	count, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(d.nodes)) {
+		return nil, fmt.Errorf("%w: %d children", ErrCorrupt, count)
+	}
+	// Leaves are the most common nodes, so only make maps that are needed
+	if count > 0 {
+		t.children = make(children, count)
+	}
+	last := int64(-1)
+	for i := uint64(0); i < count; i++ {
+		r, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if r > utf8.MaxRune || int64(r) <= last {
+			return nil, fmt.Errorf("%w: bad rune %#x", ErrCorrupt, r)
+		}
+		last = int64(r)
+		child, err := d.node(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		t.children[rune(r)] = child
+		t.heaviest = max(t.heaviest, child.heaviest)
+	}
+	return t, nil
+}
@@ -0,0 +1,134 @@
+package gospell
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	trie := NewTrie()
+	trie.InsertString("Salmon")
+	trie.InsertString("Salmonella")
+	trie.InsertStringWeighted("ab狐d犬", 42)
+	trie.InsertStringWeighted("a", -7)
+
+	data, err := trie.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	n, err := trie.WriteTo(&buf)
+	if err != nil || n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("WriteTo wrote %d bytes (%v), MarshalBinary %d", n, err, len(data))
+	}
+
+	loaded := NewTrie()
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Salmon", "Salmonella", "ab狐d犬", "a"}
+	if !HasEveryElement(t, loaded.AllFullChildren(), expected) {
+		t.Error(loaded.AllFullChildren())
+	}
+	if loaded.Weight("ab狐d犬") != 42 || loaded.Weight("a") != -7 {
+		t.Errorf("Weights weren't kept: %d %d", loaded.Weight("ab狐d犬"), loaded.Weight("a"))
+	}
+	if loaded.ContainsString("Salmo") {
+		t.Error("Prefixes shouldn't become words")
+	}
+	// Loaded leaves must still accept new words
+	loaded.InsertString("Salmonellas")
+	loaded.InsertString("ab")
+	if !loaded.ContainsString("Salmonellas") || !loaded.ContainsString("ab") {
+		t.Error("Couldn't insert into a loaded Trie")
+	}
+	loaded = NewTrie()
+	loaded.UnmarshalBinary(data)
+
+	again, err := loaded.MarshalBinary()
+	if err != nil || !bytes.Equal(again, data) {
+		t.Error("Encoding should be deterministic")
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	trie := NewTrie()
+	trie.InsertStringWeighted("toad", 3)
+	trie.InsertString("toads")
+	trie.InsertString("load")
+	data, err := trie.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{[]byte("hello, world"), ErrNotDictionary},
+		{append([]byte(binaryMagic), 99, 0, 0, 0, 0), ErrVersion},
+		{data[:len(binaryMagic)], ErrCorrupt},
+		{append(append([]byte{}, data...), 0), ErrCorrupt},
+	}
+	for _, test := range tests {
+		err := NewTrie().UnmarshalBinary(test.data)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: expected %v, got %v", test.data, test.err, err)
+		}
+	}
+
+	// No truncation or damaged byte may panic or go unnoticed
+	for i := range data {
+		if err := NewTrie().UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("Truncating to %d bytes wasn't detected", i)
+		}
+		damaged := append([]byte{}, data...)
+		damaged[i] ^= 0x5a
+		loaded := NewTrie()
+		if err := loaded.UnmarshalBinary(damaged); err == nil {
+			t.Errorf("Damaging byte %d wasn't detected", i)
+		}
+		if len(loaded.children) != 0 {
+			t.Error("A failed read shouldn't change the Trie")
+		}
+	}
+}
+
+func BenchmarkReadFrom(b *testing.B) {
+	b.StopTimer()
+	trie, err := TrieFromFile("/usr/share/dict/words")
+	if err != nil {
+		b.Fatal(err)
+	}
+	data, err := trie.MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewTrie().ReadFrom(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteTo(b *testing.B) {
+	b.StopTimer()
+	trie, err := TrieFromFile("/usr/share/dict/words")
+	if err != nil {
+		b.Fatal(err)
+	}
+	f, err := os.CreateTemp(b.TempDir(), "dict")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := trie.WriteTo(f); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
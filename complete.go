@@ -0,0 +1,112 @@
+package gospell
+
+import "container/heap"
+
+// A weighed node knows the weight of the heaviest word under it, so
+// completions can be found without visiting any lighter subtree
+type weighed interface {
+	heaviestWeight() int
+}
+
+func (t *Trie) heaviestWeight() int { return t.heaviest }
+
+// Get the k heaviest words starting with prefix, including the prefix, ranked
+// by weight with the heaviest first and then lexicographically. Only the
+// branches that can hold one of them are visited, so a short prefix is as
+// quick to complete as a long one.
+func (t *Trie) Complete(prefix string, k int) []string {
+	return complete(t, runes(prefix), k)
+}
+
+// Get the k best completions of a prefix that may be misspelled: the words
+// starting with any prefix within `distance` edits of it, ranked by the
+// Distance of their closest prefix, then by Weight, then lexicographically.
+// e.g. "recieve" is completed to "receiver".
+func (t *Trie) FuzzyComplete(prefix string, distance, k int) []string {
+	return completeFuzzy(t, runes(prefix), distance, k).Strings()
+}
+
+// A word, or a node whose words all start with word, waiting to be visited
+// in order of distance and then weight
+type completion struct {
+	word     []rune
+	n        node // nil once word is a complete word
+	weight   int  // The word's weight, or a bound on the node's words
+	distance int  // The Distance of the word, or of every word of the node
+}
+
+type completions []completion
+
+func (c completions) Len() int      { return len(c) }
+func (c completions) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+// Closest first, then heaviest, then lexicographically, as ByWeight. A
+// node's word is a prefix of all of its words, so it sorts before them and is
+// visited in time.
+func (c completions) Less(i, j int) bool {
+	if c[i].distance != c[j].distance {
+		return c[i].distance < c[j].distance
+	}
+	if c[i].weight != c[j].weight {
+		return c[i].weight > c[j].weight
+	}
+	if w1, w2 := string(c[i].word), string(c[j].word); w1 != w2 {
+		return w1 < w2
+	}
+	return c[i].n == nil
+}
+
+func (c *completions) Push(x any) { *c = append(*c, x.(completion)) }
+
+func (c *completions) Pop() any {
+	old := *c
+	x := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return x
+}
+
+// Best-first search for the k heaviest words under root starting with prefix.
+// Nodes that aren't weighed are bounded by infinity, so they are all visited
+// before any word is returned.
+func complete(root node, prefix []rune, k int) []string {
+	words := []string{}
+	n := get(root, string(prefix))
+	if n == nil || k <= 0 {
+		return words
+	}
+	queue := &completions{{word: prefix, n: n, weight: heaviestBound(n)}}
+	for _, m := range queue.take(k) {
+		words = append(words, string(m.Word))
+	}
+	return words
+}
+
+// The weight of the heaviest word under n, or infinity if n isn't weighed
+func heaviestBound(n node) int {
+	if w, ok := n.(weighed); ok {
+		return w.heaviestWeight()
+	}
+	return infinity
+}
+
+// Pop the k best words, expanding nodes as they come to the top
+func (c *completions) take(k int) Matches {
+	heap.Init(c)
+	words := Matches{}
+	for c.Len() > 0 && len(words) < k {
+		top := heap.Pop(c).(completion)
+		if top.n == nil {
+			words = append(words, Match{Word: top.word, Distance: top.distance, Weight: top.weight})
+			continue
+		}
+		if leaf, weight := top.n.terminal(); leaf {
+			heap.Push(c, completion{word: top.word, weight: weight, distance: top.distance})
+		}
+		top.n.each(func(r rune, child node) bool {
+			word := append(top.word[:len(top.word):len(top.word)], r)
+			heap.Push(c, completion{word: word, n: child, weight: heaviestBound(child), distance: top.distance})
+			return true
+		})
+	}
+	return words
+}
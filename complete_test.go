@@ -0,0 +1,265 @@
+package gospell
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	trie := testTrie()
+	trie.InsertStringWeighted("today", 20)
+	trie.InsertStringWeighted("toads", -3)
+
+	tests := []struct {
+		prefix   string
+		k        int
+		expected []string
+	}{
+		{"t", 3, []string{"the", "today", "tea"}},
+		{"to", 4, []string{"today", "toad", "tod", "toda"}},
+		{"to", 10, []string{"today", "toad", "tod", "toda", "todd", "toads"}},
+		{"toad", 1, []string{"toad"}},
+		{"x", 3, []string{}},
+		{"t", 0, []string{}},
+	}
+	layouts := map[string]interface {
+		Complete(string, int) []string
+	}{
+		"Trie":           trie,
+		"PersistentTrie": trie.Snapshot(),
+		"Radix":          trie.Compact(),
+		"MappedTrie":     mappedTrie(t, trie),
+		"DAWG":           dawgOf(t, trie),
+	}
+	for name, layout := range layouts {
+		// Every layout bounds its branches, so Complete can prune them
+		root := layout.(Lexicon).rootNode()
+		if w, ok := root.(weighed); !ok || w.heaviestWeight() != 500 {
+			t.Errorf("%v should know its heaviest weight", name)
+		}
+		for _, test := range tests {
+			completions := layout.Complete(test.prefix, test.k)
+			if !reflect.DeepEqual(completions, test.expected) {
+				t.Errorf("%v: Complete(%q, %d) = %v, expected %v",
+					name, test.prefix, test.k, completions, test.expected)
+			}
+		}
+	}
+}
+
+func TestCompleteAfterChanges(t *testing.T) {
+	trie := testTrie()
+	check := func(expected ...string) {
+		t.Helper()
+		if completions := trie.Complete("t", 2); !reflect.DeepEqual(completions, expected) {
+			t.Errorf("Expected %v, got %v", expected, completions)
+		}
+	}
+	check("the", "tea")
+	trie.InsertStringWeighted("the", 1)
+	check("tea", "the")
+	trie.RemoveString("tea")
+	check("the", "teddy")
+	trie.InsertStringWeighted("toadstool", 50)
+	check("toadstool", "the")
+	trie.RemoveString("toadstool")
+	trie.InsertStringWeighted("teddy", -1)
+	check("the", "toad")
+
+	loaded := NewTrie()
+	data, _ := trie.MarshalBinary()
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if completions := loaded.Complete("t", 2); !reflect.DeepEqual(completions, []string{"the", "toad"}) {
+		t.Errorf("Wrong completions after loading %v", completions)
+	}
+
+	radix := NewRadix()
+	radix.InsertStringWeighted("tea", 10)
+	radix.InsertStringWeighted("team", 30)
+	radix.InsertStringWeighted("te", 20)
+	if completions := radix.Complete("t", 2); !reflect.DeepEqual(completions, []string{"team", "te"}) {
+		t.Errorf("Wrong Radix completions %v", completions)
+	}
+	radix.InsertStringWeighted("team", 1)
+	if completions := radix.Complete("tea", 1); !reflect.DeepEqual(completions, []string{"tea"}) {
+		t.Errorf("Wrong Radix completions after reweighing %v", completions)
+	}
+	if w := radix.rootNode().(weighed).heaviestWeight(); w != 20 {
+		t.Errorf("The Radix should be reweighed to 20, got %d", w)
+	}
+}
+
+// Build a DAWG holding the words and weights of trie
+func dawgOf(t *testing.T, trie *Trie) *DAWG {
+	words := trie.AllFullChildren()
+	sort.Strings(words)
+	b := NewDAWGBuilder()
+	for _, w := range words {
+		if err := b.InsertWeighted(w, trie.Weight(w)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b.Finish()
+}
+
+// Write trie in the flat format and use it as a MappedTrie
+func mappedTrie(t *testing.T, trie *Trie) *MappedTrie {
+	var buf strings.Builder
+	if _, err := trie.WriteMapped(&buf); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewMappedTrie([]byte(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func BenchmarkComplete(b *testing.B) {
+	b.StopTimer()
+	trie, err := TrieFromFile("/usr/share/dict/words")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		trie.Complete("s", 10)
+	}
+}
+
+func TestFuzzyComplete(t *testing.T) {
+	trie := NewTrie()
+	trie.InsertStringWeighted("receive", 10)
+	trie.InsertStringWeighted("receiver", 5)
+	trie.InsertStringWeighted("received", 8)
+	trie.InsertStringWeighted("relieved", 20)
+	trie.InsertStringWeighted("recipe", 30)
+	trie.InsertStringWeighted("recieve", 1)
+	trie.InsertString("deceive")
+
+	tests := []struct {
+		prefix   string
+		distance int
+		k        int
+		expected []string
+	}{
+		{"recieve", 0, 5, []string{"recieve"}},
+		{"recieve", 1, 10, []string{"recieve", "relieved", "receive", "received", "receiver"}},
+		{"recieve", 1, 3, []string{"recieve", "relieved", "receive"}},
+		{"recieve", 2, 10, []string{"recieve", "relieved", "receive", "received",
+			"receiver", "recipe", "deceive"}},
+		{"recp", 1, 10, []string{"recipe", "receive", "received", "receiver",
+			"recieve"}},
+		{"xyz", 1, 10, []string{}},
+	}
+	for _, test := range tests {
+		completions := trie.FuzzyComplete(test.prefix, test.distance, test.k)
+		if !reflect.DeepEqual(completions, test.expected) {
+			t.Errorf("FuzzyComplete(%q, %d, %d) = %v, expected %v", test.prefix,
+				test.distance, test.k, completions, test.expected)
+		}
+	}
+
+	// A word's Distance is that of its closest prefix
+	for _, m := range completeFuzzy(trie, runes("recieve"), 2, 10) {
+		if string(m.Word) == "receiver" && m.Distance != 1 {
+			t.Errorf("'receiver' should be a distance of 1, got %d", m.Distance)
+		}
+	}
+
+	if !reflect.DeepEqual(trie.Compact().FuzzyComplete("recieve", 1, 2),
+		[]string{"recieve", "relieved"}) {
+		t.Error("Radix and Trie should complete the same")
+	}
+}
+
+func TestFuzzyCompleteMatchesClosestPrefix(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	word := func(n int) []rune {
+		w := make([]rune, n)
+		for i := range w {
+			w[i] = rune('a' + rng.Intn(4))
+		}
+		return w
+	}
+	trie := NewTrie()
+	for i := 0; i < 300; i++ {
+		trie.InsertStringWeighted(string(word(1+rng.Intn(7))), rng.Intn(50))
+	}
+
+	for i := 0; i < 40; i++ {
+		prefix, distance, k := word(rng.Intn(4)), rng.Intn(3), 1+rng.Intn(8)
+		expected := Matches{}
+		trie.Walk(nil, func(w string, weight int) bool {
+			r, closest := runes(w), infinity
+			for j := 0; j <= len(r); j++ {
+				closest = min(closest, weightedDistance(prefix, r[:j], SuggestOptions{}))
+			}
+			if closest <= distance {
+				expected = append(expected, Match{Word: r, Distance: closest, Weight: weight})
+			}
+			return true
+		})
+		sort.Sort(ByWeight{expected})
+		expected = expected[:min(k, len(expected))]
+
+		completions := completeFuzzy(trie, prefix, distance, k)
+		if len(completions) != len(expected) {
+			t.Fatalf("completeFuzzy(%q, %d, %d) = %v, expected %v",
+				string(prefix), distance, k, completions, expected)
+		}
+		for j := range expected {
+			if !completions[j].Equal(expected[j]) {
+				t.Errorf("completeFuzzy(%q, %d, %d): expected %v at %d, got %v",
+					string(prefix), distance, k, expected[j], j, completions[j])
+			}
+		}
+	}
+}
+
+// A node that counts the nodes visited below it
+type countingNode struct {
+	node
+	visits *int
+}
+
+func (n countingNode) heaviestWeight() int { return n.node.(weighed).heaviestWeight() }
+
+func (n countingNode) child(r rune) node {
+	if c := n.node.child(r); c != nil {
+		*n.visits++
+		return countingNode{c, n.visits}
+	}
+	return nil
+}
+
+func (n countingNode) each(fn func(rune, node) bool) bool {
+	return n.node.each(func(r rune, c node) bool {
+		*n.visits++
+		return fn(r, countingNode{c, n.visits})
+	})
+}
+
+func TestFuzzyCompleteVisitsFewNodes(t *testing.T) {
+	trie := NewTrie()
+	for i := 0; i < 20000; i++ {
+		trie.InsertStringWeighted(strconv.Itoa(i*7919), i%1000)
+	}
+	nodes := 0
+	trie.Walk(nil, func(string, int) bool { nodes++; return true })
+
+	visits := 0
+	completions := completeFuzzy(countingNode{trie, &visits}, runes("12"), 2, 10)
+	if len(completions) != 10 {
+		t.Fatalf("Expected 10 completions, got %v", completions)
+	}
+	if visits > nodes/4 {
+		t.Errorf("A prefix no longer than the distance visited %d nodes of %d words", visits, nodes)
+	}
+}
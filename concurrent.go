@@ -0,0 +1,134 @@
+package gospell
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// A ConcurrentTrie is a Trie that is safe for concurrent use. Any number of
+// goroutines may look up words and ask for suggestions while others insert or
+// remove words. Its words are held in a PersistentTrie: readers never wait,
+// each using the version current when it starts, while a writer builds the
+// next version and swaps it in. Writers wait for each other, but inserting or
+// removing a single word is very quick.
+type ConcurrentTrie struct {
+	mu    sync.Mutex // Held by writers
+	words atomic.Pointer[PersistentTrie]
+}
+
+// Create a ConcurrentTrie holding a copy of the words of t, such as a Trie
+// returned by TrieFromFile. If t is nil the ConcurrentTrie starts empty.
+func NewConcurrentTrie(t *Trie) *ConcurrentTrie {
+	c := new(ConcurrentTrie)
+	if t == nil {
+		c.words.Store(NewPersistentTrie())
+	} else {
+		c.words.Store(t.Snapshot())
+	}
+	return c
+}
+
+// Get the current version of the words, which later changes leave as it is
+func (c *ConcurrentTrie) Snapshot() *PersistentTrie {
+	return c.words.Load()
+}
+
+func (c *ConcurrentTrie) rootNode() node { return c.Snapshot().root }
+
+// Replace the current version with the one f makes from it
+func (c *ConcurrentTrie) write(f func(*PersistentTrie) *PersistentTrie) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.words.Store(f(c.words.Load()))
+}
+
+// Insert a string into the Trie
+func (c *ConcurrentTrie) InsertString(s string) {
+	c.write(func(p *PersistentTrie) *PersistentTrie { return p.InsertString(s) })
+}
+
+// Insert a string into the Trie with a weight. See Trie.InsertWeighted.
+func (c *ConcurrentTrie) InsertStringWeighted(s string, weight int) {
+	c.write(func(p *PersistentTrie) *PersistentTrie { return p.InsertStringWeighted(s, weight) })
+}
+
+// Remove a string from the Trie. See Trie.Remove.
+func (c *ConcurrentTrie) RemoveString(s string) bool {
+	removed := false
+	c.write(func(p *PersistentTrie) *PersistentTrie {
+		p, removed = p.RemoveString(s)
+		return p
+	})
+	return removed
+}
+
+// Check if a String is Contained in the Trie
+func (c *ConcurrentTrie) ContainsString(s string) bool {
+	return c.Snapshot().ContainsString(s)
+}
+
+// Get the weight of a word in the Trie, or 0 if it isn't Contained
+func (c *ConcurrentTrie) Weight(s string) int {
+	return c.Snapshot().Weight(s)
+}
+
+// Get all of the words in the Trie, in rune order
+func (c *ConcurrentTrie) AllFullChildren() []string {
+	return c.Snapshot().AllFullChildren()
+}
+
+// See Trie.Walk. The words are those of the version current when the walk
+// starts, so fn may change the ConcurrentTrie.
+func (c *ConcurrentTrie) Walk(col Collation, fn func(word string, weight int) bool) bool {
+	return c.Snapshot().Walk(col, fn)
+}
+
+// See Trie.Complete
+func (c *ConcurrentTrie) Complete(prefix string, k int) []string {
+	return c.Snapshot().Complete(prefix, k)
+}
+
+// See Trie.FuzzyComplete
+func (c *ConcurrentTrie) FuzzyComplete(prefix string, distance, k int) []string {
+	return c.Snapshot().FuzzyComplete(prefix, distance, k)
+}
+
+// See Trie.Deletions
+func (c *ConcurrentTrie) Deletions(s string, distance int) []string {
+	return c.Snapshot().Deletions(s, distance)
+}
+
+// See Trie.Additions
+func (c *ConcurrentTrie) Additions(s string, distance int) []string {
+	return c.Snapshot().Additions(s, distance)
+}
+
+// See Trie.Substitutions
+func (c *ConcurrentTrie) Substitutions(s string, distance int) []string {
+	return c.Snapshot().Substitutions(s, distance)
+}
+
+// See Trie.Transpositions
+func (c *ConcurrentTrie) Transpositions(s string, distance int) []string {
+	return c.Snapshot().Transpositions(s, distance)
+}
+
+// See Trie.Anagrams
+func (c *ConcurrentTrie) Anagrams(s string) []string {
+	return c.Snapshot().Anagrams(s)
+}
+
+// See Trie.Suggest
+func (c *ConcurrentTrie) Suggest(s string, opts SuggestOptions) Matches {
+	return c.Snapshot().Suggest(s, opts)
+}
+
+// See Trie.SuggestWords
+func (c *ConcurrentTrie) SuggestWords(s string, distance int) []string {
+	return c.Snapshot().SuggestWords(s, distance)
+}
+
+// See Trie.SuggestTopK
+func (c *ConcurrentTrie) SuggestTopK(s string, distance, k int) []string {
+	return c.Snapshot().SuggestTopK(s, distance, k)
+}
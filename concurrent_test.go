@@ -0,0 +1,62 @@
+package gospell
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Run with -race to check for data races
+func TestConcurrentTrie(t *testing.T) {
+	c := NewConcurrentTrie(testTrie())
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				word := fmt.Sprintf("toad%d%d", i, j)
+				c.InsertStringWeighted(word, j)
+				if !c.ContainsString(word) {
+					t.Errorf("%q wasn't inserted", word)
+				}
+				if j%2 == 0 && !c.RemoveString(word) {
+					t.Errorf("%q wasn't removed", word)
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				suggestions := c.SuggestWords("toad", 1)
+				if len(suggestions) == 0 || suggestions[0] != "toad" {
+					t.Errorf("Wrong suggestions %v", suggestions)
+				}
+				c.Deletions("toads", 1)
+				c.Additions("toa", 2)
+				c.Substitutions("toed", 1)
+				c.Transpositions("otad", 1)
+				c.Anagrams("daot")
+				c.Weight("the")
+				c.AllFullChildren()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 200; j++ {
+			word := fmt.Sprintf("toad%d%d", i, j)
+			if c.ContainsString(word) != (j%2 == 1) {
+				t.Errorf("%q should be Contained: %t", word, j%2 == 1)
+			}
+		}
+	}
+	if c.Weight("toad01") != 1 {
+		t.Errorf("Weight of 'toad01' is %d", c.Weight("toad01"))
+	}
+}
@@ -1,33 +1,58 @@
 package gospell
 
-import "sort"
+import "unicode"
 
 // Find all strings in the trie within a given deletion distance
 // For example, for the Trie{"abcd", "abc", "ab", "cd"},
 // Deletions("abcd", 2) would return ["ab", "cd"] and
 // Deletions("abcd", 1) would return ["abc"]
 func (t *Trie) Deletions(s string, distance int) []string {
-	return t.deletions(runes(s), distance).Strings()
+	return t.Suggest(s, SuggestOptions{Distance: distance, Edits: Deletions}).Strings()
 }
 
 // Find all words in the Trie adding at most `distance` runes
 func (t *Trie) Additions(s string, distance int) []string {
-	return t.additions(runes(s), distance).Strings()
+	return t.Suggest(s, SuggestOptions{Distance: distance, Edits: Additions}).Strings()
 }
 
-// Find all words in the Trie adding at most `distance` runes
+// Find all words in the Trie substituting at most `distance` runes
 func (t *Trie) Substitutions(s string, distance int) []string {
-	return t.substitutions(runes(s), distance).Strings()
+	return t.Suggest(s, SuggestOptions{Distance: distance, Edits: Substitutions}).Strings()
+}
+
+// Find all words in the Trie swapping at most `distance` pairs of adjacent
+// runes. For example, Transpositions("teh", 1) would return ["the"]
+func (t *Trie) Transpositions(s string, distance int) []string {
+	return t.Suggest(s, SuggestOptions{Distance: distance, Edits: Transpositions}).Strings()
 }
 
-// Find all strings matching permutations of the given distance
+// Find all words in the Trie that use exactly the runes of s in any order,
+// ranked by the number of runes that moved
+func (t *Trie) Anagrams(s string) []string {
+	r := runes(s)
+	return anagrams(t, r, r).Strings()
+}
+
+// Find all strings matching permutations of s.
+//
+// Deprecated: Permutations ignores distance and returns every anagram. Use
+// Transpositions for adjacent swaps or Anagrams for full permutations.
 func (t *Trie) Permutations(s string, distance int) []string {
-	return t.permutations(runes(s), distance).Strings()
+	return t.Anagrams(s)
 }
 
-// Return spelling suggestions, ranked by Distance then lexicographically
+// Return spelling suggestions reachable by any mix of at most `distance`
+// additions, deletions, substitutions and transpositions, ranked by Distance,
+// then by Weight, then lexicographically
 func (t *Trie) SuggestWords(s string, distance int) []string {
-	return t.suggestions(runes(s), distance).Strings()
+	return t.Suggest(s, SuggestOptions{Distance: distance}).Strings()
+}
+
+// Return the k best spelling suggestions of SuggestWords. Only k suggestions
+// are kept during the search, and branches that can't beat the k-th best are
+// skipped, so it is much faster than SuggestWords for large distances.
+func (t *Trie) SuggestTopK(s string, distance, k int) []string {
+	return suggestTopK(t, runes(s), distance, k).Strings()
 }
 
 // Convert a string into a slice of runes
@@ -47,181 +72,353 @@ func runes(s string) []rune {
 	return runes
 }
 
-func (t *Trie) deletions(r []rune, distance int) Matches {
+// The kinds of edit a search may make to its input
+type Edits uint8
+
+const (
+	// Add a rune to the input
+	Additions Edits = 1 << iota
+	// Delete a rune from the input
+	Deletions
+	// Replace a rune of the input
+	Substitutions
+	// Swap two adjacent runes of the input
+	Transpositions
+	AllEdits = Additions | Deletions | Substitutions | Transpositions
+)
+
+// Larger than any distance a search can be asked for
+const infinity = int(^uint(0) >> 2)
+
+// Find all words under root within `distance` of r using only the allowed
+// edits. Each word is returned once with its smallest Distance.
+func search(root node, r []rune, distance int, allowed Edits) Matches {
 	matches := Matches{}
+	s := newSearcher(r, distance, allowed, func(m Match) bool {
+		matches = append(matches, m)
+		return true
+	})
+	s.walk(root)
+	return matches
+}
 
-	if len(r) == 0 {
-		if t.leaf {
-			matches = append(matches, Match{})
-		}
-		return matches
+// A searcher walks a dictionary alongside the rows of the edit distance table
+// between its input and the word spelled by the path from the root. Row i
+// holds, for every prefix of the input, the cheapest way to turn it into the
+// first i runes of the path. Moving to a child computes the next row from the
+// last two, and a subtree is skipped as soon as no entry of its row can
+// lead to a word within distance.
+type searcher struct {
+	r        []rune
+	distance int
+	allowed  Edits
+	yield    func(Match) bool
+	word     []rune
+	rows     [][]int
+	// Whether r is a prefix, so that every word below a node within distance
+	// of it matches
+	prefix bool
+	// For a prefix search, the smallest distance of r from any node on the
+	// path so far
+	reached []int
+	// For a prefix search, called instead of visiting n once no node below it
+	// can be closer to r than one on the path, so that every word below n has
+	// the given distance. Returns false to stop the search.
+	settle func(word []rune, n node, distance int) bool
+	// Whether to visit the child following r before the others, so that
+	// close words are found early
+	greedy bool
+	// The cost of each kind of edit
+	addCost, deleteCost, substituteCost, transposeCost int
+	// Costs of substituting particular runes, overriding substituteCost
+	matrix SubstitutionMatrix
+	// The keyboard r was typed on, and the cost of slips of neighbouring keys
+	keyboard *KeyboardLayout
+	keyCost  int
+	// The cost of deleting each rune of r, indexed from 1
+	deleteCosts []int
+	// Whether runes are compared ignoring case, in which case r is lower case
+	fold bool
+}
+
+func newSearcher(r []rune, distance int, allowed Edits, yield func(Match) bool) *searcher {
+	return &searcher{r: r, distance: distance, allowed: allowed, yield: yield,
+		addCost: 1, deleteCost: 1, substituteCost: 1, transposeCost: 1, keyCost: 1}
+}
+
+// Compare runes ignoring case
+func (s *searcher) ignoreCase() {
+	s.fold = true
+	folded := make([]rune, len(s.r))
+	for i, c := range s.r {
+		folded[i] = unicode.ToLower(c)
 	}
+	s.r = folded
+}
 
-	// Two cases:
-	// 1. Pop the first rune from the list, recurse on the child with that rune
-	//  as the key
-	// 2. Pop the first rune from the list, recurse on the current node
-	//  (effectively ignoring this rune)
+// The rune at position i of the path, lower cased if ignoring case
+func (s *searcher) at(i int) rune {
+	if s.fold {
+		return unicode.ToLower(s.word[i])
+	}
+	return s.word[i]
+}
 
-	first := r[0]
-	rest := r[1:]
-	// Case 1
-	child := t.children[first]
-	if child != nil {
-		childMatches := child.deletions(rest, distance)
-		for _, m := range childMatches {
-			matches = append(matches, m.update(first, 0, 0))
+// The cost of substituting b for a
+func (s *searcher) substitution(a, b rune) int {
+	if s.matrix != nil {
+		if cost, ok := s.matrix[[2]rune{a, b}]; ok {
+			return max(cost, 0)
 		}
 	}
-	// Case 2
-	if distance > 0 {
-		childMatches := t.deletions(rest, distance-1)
-		for _, m := range childMatches {
-			matches = append(matches, m.update(0, 2, 0))
-		}
+	if s.keyboard != nil && s.keyboard.Adjacent(a, b) {
+		return min(s.substituteCost, s.keyCost)
 	}
+	return s.substituteCost
+}
 
-	return matches
+// The cost of adding c after the first j runes of r. A key dropped beside,
+// or on the same key as, the one before or after it is cheaper to add back.
+func (s *searcher) addition(j int, c rune) int {
+	if s.keyboard != nil && (j > 0 && s.keyboard.slip(s.r[j-1], c) ||
+		j < len(s.r) && s.keyboard.slip(s.r[j], c)) {
+		return min(s.addCost, s.keyCost)
+	}
+	return s.addCost
 }
 
-// Find all permutations of r that exist in the trie
-// This does not currently respect the distance parameter
-func (t *Trie) permutations(r []rune, distance int) Matches {
-	matches := Matches{}
+// Walk the nodes under root, yielding each word within distance. Returns
+// false if the yield function asked to stop.
+func (s *searcher) walk(root node) bool {
+	s.start()
+	return s.visit(root, 0)
+}
 
-	if len(r) == 0 {
-		if t.leaf {
-			matches = append(matches, Match{})
+// The cost of the cheapest edits turning r into word, however many. Every
+// row of the table is filled in, without pruning.
+func (s *searcher) cost(word []rune) int {
+	s.start()
+	s.word = word
+	for depth := 1; depth <= len(word); depth++ {
+		s.rows = append(s.rows, make([]int, len(s.r)+1))
+		s.step(depth)
+	}
+	return s.rows[len(word)][len(s.r)]
+}
+
+// Fill in the first row of the table
+func (s *searcher) start() {
+	// A rune typed by slipping onto a key beside, or the same as, the key
+	// before or after it is cheaper to delete
+	s.deleteCosts = make([]int, len(s.r)+1)
+	for j := 1; j <= len(s.r); j++ {
+		s.deleteCosts[j] = s.deleteCost
+		if s.keyboard != nil && (j > 1 && s.keyboard.slip(s.r[j-2], s.r[j-1]) ||
+			j < len(s.r) && s.keyboard.slip(s.r[j], s.r[j-1])) {
+			s.deleteCosts[j] = min(s.deleteCost, s.keyCost)
 		}
-		return matches
 	}
 
-	for i, c := range r {
-		// If we don't make a new slice things get overwritten... not sure why
-		rest := make([]rune, 0)
-		rest = append(rest, r[:i]...)
-		rest = append(rest, r[i+1:]...)
-		child := t.children[c]
-		if child != nil {
-			childMatches := child.permutations(rest, distance)
-			for _, cr := range childMatches {
-				matches = append(matches, cr.update(c, 2, 0))
-			}
+	// The first row is the cost of deleting each prefix of the input
+	first := make([]int, len(s.r)+1)
+	for j := 1; j <= len(s.r); j++ {
+		if s.allowed&Deletions != 0 {
+			first[j] = first[j-1] + s.deleteCosts[j]
+		} else {
+			first[j] = infinity
 		}
 	}
+	s.rows = [][]int{first}
+	s.reached = []int{first[len(s.r)]}
+}
 
-	return matches
+func (s *searcher) visit(n node, depth int) bool {
+	distance := s.rows[depth][len(s.r)]
+	if s.prefix {
+		distance = s.reached[depth]
+		if s.settle != nil && s.settled(depth) {
+			return distance > s.distance ||
+				s.settle(append([]rune{}, s.word...), n, distance)
+		}
+	}
+	if leaf, weight := n.terminal(); leaf && distance <= s.distance {
+		m := Match{
+			Word:     append([]rune{}, s.word...),
+			Distance: distance,
+			Weight:   weight,
+		}
+		if !s.yield(m) {
+			return false
+		}
+	}
+	if depth+1 == len(s.rows) {
+		s.rows = append(s.rows, make([]int, len(s.r)+1))
+		s.reached = append(s.reached, 0)
+	}
+	next := func(c rune, child node) bool {
+		s.word = append(s.word, c)
+		ok := !s.step(depth+1) || s.visit(child, depth+1)
+		s.word = s.word[:depth]
+		return ok
+	}
+	if !s.greedy || depth >= len(s.r) {
+		return n.each(next)
+	}
+	// Follow the input first, where the closest words are likely to be. When
+	// ignoring case, only the lower case child is followed first.
+	c := s.r[depth]
+	if child := n.child(c); child != nil && !next(c, child) {
+		return false
+	}
+	return n.each(func(r rune, child node) bool {
+		return r == c || next(r, child)
+	})
 }
 
-func (t *Trie) additions(r []rune, distance int) Matches {
-	matches := Matches{}
+// Fill in the row for the last rune of s.word, which is at the given depth.
+// Returns false if no word below it can be within distance.
+func (s *searcher) step(depth int) bool {
+	c := s.at(depth - 1)
+	prev := s.rows[depth-1]
+	row := s.rows[depth]
+	best := infinity
 
-	// Three cases:
-	// 0. All runes have been seen, but we have distance to spare, so add runes
-	// 1. Pop the first rune from the list, recurse on the child with that rune
-	//   as the key
-	// 2. Recurse on all children of the current node, effectively adding the
-	//   key for the child to the word
-	if len(r) == 0 {
-		// Case 0: no more runes but more to add
-		if t.leaf {
-			matches = append(matches, Match{})
-		}
+	if s.allowed&Additions != 0 {
+		row[0] = prev[0] + s.addition(0, c)
 	} else {
-		// Case 1
-		first := r[0]
-		rest := r[1:]
-		child, ok := t.children[first]
-		if ok {
-			childMatches := child.additions(rest, distance)
-			for _, cr := range childMatches {
-				matches = append(matches, cr.update(first, 0, 0))
-			}
+		row[0] = infinity
+	}
+	for j := 1; j <= len(s.r); j++ {
+		cost := infinity
+		if s.r[j-1] == c {
+			cost = prev[j-1]
+		} else if s.allowed&Substitutions != 0 {
+			cost = min(cost, prev[j-1]+s.substitution(s.r[j-1], c))
+		}
+		if s.allowed&Additions != 0 {
+			cost = min(cost, prev[j]+s.addition(j, c))
+		}
+		if s.allowed&Deletions != 0 {
+			cost = min(cost, row[j-1]+s.deleteCosts[j])
+		}
+		if s.allowed&Transpositions != 0 && depth > 1 && j > 1 &&
+			s.r[j-1] == s.at(depth-2) && s.r[j-2] == c && c != s.at(depth-2) {
+			cost = min(cost, s.rows[depth-2][j-2]+s.transposeCost)
 		}
+		row[j] = cost
+		best = min(best, cost)
 	}
+	best = min(best, row[0])
 
-	// Case 2
-	if distance > 0 {
-		for c, child := range t.children {
-			childMatches := child.additions(r, distance-1)
-			for _, cr := range childMatches {
-				matches = append(matches, cr.update(c, 1, 0))
+	if s.prefix {
+		// Every word below a node matching the prefix matches
+		s.reached[depth] = min(s.reached[depth-1], row[len(s.r)])
+		if s.reached[depth] <= s.distance {
+			return true
+		}
+	}
+	if best <= s.distance {
+		return true
+	}
+	// A transposition reaches back past this row, so a path this row rules
+	// out may still be recovered from the previous one. Substitutions don't
+	// always carry the previous row forward, as they may cost more.
+	if s.allowed&Transpositions != 0 {
+		for _, cost := range prev {
+			if cost+s.transposeCost <= s.distance {
+				return true
 			}
 		}
 	}
+	return false
+}
 
-	return matches
+// Whether no path below depth can come closer to the prefix than the closest
+// node on the path so far. Costs aren't negative, so no later row can hold
+// less than this row, or than the one before it plus a transposition.
+func (s *searcher) settled(depth int) bool {
+	bound := infinity
+	for _, cost := range s.rows[depth] {
+		bound = min(bound, cost)
+	}
+	if s.allowed&Transpositions != 0 && depth > 0 {
+		for _, cost := range s.rows[depth-1] {
+			bound = min(bound, cost+s.transposeCost)
+		}
+	}
+	return bound >= s.reached[depth]
 }
 
-func (t *Trie) substitutions(r []rune, distance int) Matches {
+func suggest(root node, r []rune, distance int) Matches {
+	return suggestWith(root, r, SuggestOptions{Distance: distance})
+}
+
+func suggestTopK(root node, r []rune, distance, k int) Matches {
+	if k <= 0 {
+		return Matches{}
+	}
+	return suggestWith(root, r, SuggestOptions{Distance: distance, Limit: k})
+}
+
+// Find the k best words under root starting with a prefix within distance of
+// r, ranked ByWeight. A word's Distance is that of its closest prefix. The
+// edit distance search stops at the first node below which no prefix is
+// closer, and the words below those nodes are found best first, as by
+// complete, so only the branches that can hold one of the k best are visited.
+func completeFuzzy(root node, r []rune, distance, k int) Matches {
+	if k <= 0 {
+		return Matches{}
+	}
+	queue := &completions{}
+	s := newSearcher(r, distance, AllEdits, func(m Match) bool {
+		*queue = append(*queue, completion{word: m.Word, weight: m.Weight, distance: m.Distance})
+		return true
+	})
+	s.prefix = true
+	s.settle = func(word []rune, n node, distance int) bool {
+		*queue = append(*queue, completion{word: word, n: n, weight: heaviestBound(n), distance: distance})
+		return true
+	}
+	s.walk(root)
+	return queue.take(k)
+}
+
+// Find all permutations of the remaining runes r that exist under n.
+// orig is the full input, used to count the runes that moved.
+func anagrams(n node, orig, r []rune) Matches {
 	matches := Matches{}
 
 	if len(r) == 0 {
-		if t.leaf {
-			matches = append(matches, Match{})
+		if leaf, weight := n.terminal(); leaf {
+			matches = append(matches, Match{Weight: weight})
 		}
 		return matches
 	}
 
-	// Two cases:
-	// 1. Pop the first rune from the list, recurse on the child with that rune
-	//  as the key
-	// 2. Pop the first rune from the list, recurse on the current node
-	//  (effectively ignoring this rune)
-	first := r[0]
-	rest := r[1:]
-	for c, child := range t.children {
+	pos := len(orig) - len(r)
+	seen := make(map[rune]bool)
+	for i, c := range r {
+		// Repeated runes would otherwise produce the same words twice
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		child := n.child(c)
 		if child == nil {
 			continue
 		}
+		// If we don't make a new slice things get overwritten... not sure why
+		rest := make([]rune, 0, len(r)-1)
+		rest = append(rest, r[:i]...)
+		rest = append(rest, r[i+1:]...)
 		d := 0
-		childMatches := Matches{}
-		if c == first {
-			// Case 1
-			childMatches = child.substitutions(rest, distance)
-		} else if distance > 0 {
-			// Case 2
-			childMatches = child.substitutions(rest, distance-1)
+		if c != orig[pos] {
 			d = 1
-		} else {
-			continue
 		}
-
-		for _, cr := range childMatches {
+		for _, cr := range anagrams(child, orig, rest) {
 			matches = append(matches, cr.update(c, d, 0))
 		}
 	}
-	return matches
-}
 
-func (t *Trie) suggestions(r []rune, distance int) Matches {
-	suggestions := Matches{}
-	additions := t.additions(r, distance)
-	deletions := t.deletions(r, distance)
-	permutations := t.permutations(r, distance)
-	substitutions := t.substitutions(r, distance)
-
-	unique := func(matches Matches) Matches {
-		dupes := make(map[string]int)
-		ret := make(Matches, len(matches))
-		i := 0
-		for _, match := range matches {
-			word := string(match.Word)
-			if _, ok := dupes[word]; !ok {
-				ret[i] = match
-				dupes[word] = 1
-				i += 1
-			}
-		}
-		return ret[:i]
-	}
-
-	// Combine and remove duplicates
-	suggestions = append(suggestions, additions...)
-	suggestions = append(suggestions, deletions...)
-	suggestions = append(suggestions, permutations...)
-	suggestions = append(suggestions, substitutions...)
-	// Sort by distance so we only keep the lowest scoring match
-	sort.Sort(ByDistance{suggestions})
-	return unique(suggestions)
+	return matches
 }
@@ -1,6 +1,8 @@
 package gospell
 
 import (
+	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -134,6 +136,66 @@ func TestPermutations(t *testing.T) {
 	assertAllIn(t, expected, permutations)
 }
 
+func TestAnagrams(t *testing.T) {
+	s1 := "aab"
+	expected := []string{
+		s1,
+		"aba",
+		"baa"}
+	trie := NewTrie()
+	for _, s := range expected {
+		trie.InsertString(s)
+	}
+	trie.InsertString("ab")
+	trie.InsertString("abb")
+
+	anagrams := trie.Anagrams(s1)
+	if len(anagrams) != len(expected) {
+		t.Errorf("Anagrams has the wrong number of words %v", anagrams)
+	}
+	assertAllIn(t, expected, anagrams)
+	// Ranked by the number of runes that moved
+	if anagrams[0] != s1 || anagrams[1] != "aba" {
+		t.Errorf("Anagrams are in the wrong order %v", anagrams)
+	}
+}
+
+func TestTranspositions(t *testing.T) {
+	s1 := "ab狐d犬"
+	expected1 := []string{
+		s1,
+		"ba狐d犬",
+		"ab狐犬d"}
+	expected2 := []string{
+		"ba狐犬d",
+		"bad狐犬"}
+	s2 := "犬d狐ba"
+	s3 := "狐bad犬"
+
+	trie := NewTrie()
+	trie.InsertString(s2)
+	trie.InsertString(s3)
+	for _, s := range expected1 {
+		trie.InsertString(s)
+	}
+	for _, s := range expected2 {
+		trie.InsertString(s)
+	}
+
+	transpositions := trie.Transpositions(s1, 1)
+	if len(transpositions) != len(expected1) {
+		t.Errorf("Transpositions has the wrong number of words %v", transpositions)
+	}
+	assertAllIn(t, expected1, transpositions)
+
+	expected2 = append(expected2, expected1...)
+	transpositions = trie.Transpositions(s1, 2)
+	if len(transpositions) != len(expected2) {
+		t.Errorf("Transpositions has the wrong number of words %v", transpositions)
+	}
+	assertAllIn(t, expected2, transpositions)
+}
+
 func TestAdditions(t *testing.T) {
 	s1 := "ab狐d犬"
 	expected1 := []string{
@@ -221,12 +283,12 @@ func TestSuggestions(t *testing.T) {
 		"load",
 		"toads",
 		"tod",
-		"toda"}
+		"toda",
+		"bad",   // A substitution and a deletion
+		"today"} // A transposition and an addition
 	unexpected := []string{
 		"robert",
-		"today", // Note: today should probably be suggested in the future
-		"teddy",
-		"bad"}
+		"teddy"}
 
 	trie := NewTrie()
 	trie.InsertString(s1)
@@ -242,6 +304,132 @@ func TestSuggestions(t *testing.T) {
 		t.Errorf("Suggestions has the wrong number of words %v", suggestions)
 	}
 	assertAllIn(t, expected, suggestions)
+
+	// Transpositions compose with the other edits
+	trie = NewTrie()
+	trie.InsertString("the")
+	trie.InsertString("thee")
+	trie.InsertString("tea")
+	suggestions = trie.SuggestWords("teh", 1)
+	if len(suggestions) != 2 || suggestions[0] != "tea" || suggestions[1] != "the" {
+		t.Errorf("Wrong suggestions for 'teh' %v", suggestions)
+	}
+	suggestions = trie.SuggestWords("teh", 2)
+	if len(suggestions) != 3 || suggestions[2] != "thee" {
+		t.Errorf("Wrong suggestions for 'teh' %v", suggestions)
+	}
+}
+
+// The optimal string alignment distance between a and b, computed with the
+// full table
+func editDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func TestSearchMatchesEditDistance(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	word := func() []rune {
+		w := make([]rune, 1+rng.Intn(6))
+		for i := range w {
+			w[i] = rune('a' + rng.Intn(4))
+		}
+		return w
+	}
+
+	trie := NewTrie()
+	words := map[string]bool{}
+	for i := 0; i < 300; i++ {
+		w := string(word())
+		words[w] = true
+		trie.InsertString(w)
+	}
+
+	for i := 0; i < 50; i++ {
+		r := word()
+		for distance := 0; distance <= 2; distance++ {
+			found := map[string]int{}
+			for _, m := range search(trie, r, distance, AllEdits) {
+				found[string(m.Word)] = m.Distance
+			}
+			for w := range words {
+				d := editDistance(r, runes(w))
+				got, ok := found[w]
+				if d <= distance && (!ok || got != d) {
+					t.Errorf("%q -> %q: want distance %d, got %d (found %t)",
+						string(r), w, d, got, ok)
+				}
+				if d > distance && ok {
+					t.Errorf("%q -> %q: distance %d found within %d",
+						string(r), w, d, distance)
+				}
+			}
+		}
+	}
+}
+
+func TestWeightedSuggestions(t *testing.T) {
+	trie := NewTrie()
+	trie.InsertStringWeighted("tea", 10)
+	trie.InsertStringWeighted("the", 500)
+	trie.InsertStringWeighted("ten", 10)
+	trie.InsertStringWeighted("teh", 1)
+	trie.InsertString("thee")
+
+	expected := []string{"teh", "the", "tea", "ten", "thee"}
+	suggestions := trie.SuggestWords("teh", 2)
+	if len(suggestions) != len(expected) {
+		t.Fatalf("Suggestions has the wrong number of words %v", suggestions)
+	}
+	for i, s := range expected {
+		if suggestions[i] != s {
+			t.Errorf("Suggestions are in the wrong order %v", suggestions)
+			break
+		}
+	}
+
+	for _, m := range suggest(trie, runes("teh"), 1) {
+		if m.Weight != trie.Weight(string(m.Word)) {
+			t.Errorf("%v has the wrong weight", m)
+		}
+	}
+}
+
+func TestSuggestTopK(t *testing.T) {
+	trie := testTrie()
+	trie.InsertStringWeighted("toads", 7)
+	for _, s := range []string{"toad", "tead", "bod", "ba狐d", "xyzzy", ""} {
+		for _, distance := range []int{0, 1, 2, 3} {
+			all := trie.SuggestWords(s, distance)
+			for k := 0; k <= len(all)+1; k++ {
+				expected := all[:min(k, len(all))]
+				topK := trie.SuggestTopK(s, distance, k)
+				if !reflect.DeepEqual(topK, expected) {
+					t.Errorf("SuggestTopK(%q, %d, %d) = %v, expected %v",
+						s, distance, k, topK, expected)
+				}
+			}
+		}
+	}
 }
 
 func TestLoadDict(t *testing.T) {
@@ -256,25 +444,37 @@ func TestLoadDict(t *testing.T) {
 	suggestions := trie.SuggestWords("hyllo", 1)
 	expected := []string{
 		"hello",
-		//"hell",  // This doesn't show up because it's a sub & deletion FIXME
 		"holly",
 		"hollo"}
 	if len(expected) != len(suggestions) {
 		t.Errorf("Suggestions has the wrong number of words %v", suggestions)
 	}
 	assertAllIn(t, expected, suggestions)
+
+	// "hell" is a substitution and a deletion away
+	found := false
+	for _, s := range trie.SuggestWords("hyllo", 2) {
+		if s == "hell" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("'hell' not suggested for 'hyllo'")
+	}
 }
 
 func TestDistance(t *testing.T) {
 	s1 := "toad"
 	// Ensure sorting is correct
 	expectedOrdered := Matches{
-		Match{runes(s1), 0, 0},
-		Match{runes("load"), 1, 0},
-		Match{runes("toads"), 1, 0},
-		Match{runes("todd"), 1, 0},
-		Match{runes("tod"), 2, 0},
-		Match{runes("toda"), 2, 0},
+		{Word: runes(s1), Distance: 0},
+		{Word: runes("load"), Distance: 1},
+		{Word: runes("toads"), Distance: 1},
+		{Word: runes("tod"), Distance: 1},
+		{Word: runes("toda"), Distance: 1},
+		{Word: runes("todd"), Distance: 1},
+		{Word: runes("bad"), Distance: 2},
+		{Word: runes("today"), Distance: 2},
 	}
 	expected := make([]string, len(expectedOrdered))
 	for i, match := range expectedOrdered {
@@ -282,9 +482,7 @@ func TestDistance(t *testing.T) {
 	}
 	unexpected := []string{
 		"robert",
-		"today", // Note: today should probably be suggested in the future
-		"teddy",
-		"bad"}
+		"teddy"}
 
 	trie := NewTrie()
 	trie.InsertString(s1)
@@ -295,7 +493,7 @@ func TestDistance(t *testing.T) {
 		trie.InsertString(s)
 	}
 
-	suggestions := trie.suggestions(runes(s1), 2)
+	suggestions := suggest(trie, runes(s1), 2)
 	if len(expected) != len(suggestions) {
 		t.Errorf("Suggestions has the wrong number of matches %v", suggestions)
 	}
@@ -308,43 +506,51 @@ func TestDistance(t *testing.T) {
 }
 
 func BenchmarkAdditions1(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.additions(r, 1) })
+	benchmarkOp(b, func(trie *Trie, r []rune) { search(trie, r, 1, Additions) })
 }
 
 func BenchmarkAdditions2(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.additions(r, 2) })
+	benchmarkOp(b, func(trie *Trie, r []rune) { search(trie, r, 2, Additions) })
 }
 
 func BenchmarkDeletions1(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.deletions(r, 1) })
+	benchmarkOp(b, func(trie *Trie, r []rune) { search(trie, r, 1, Deletions) })
 }
 
 func BenchmarkDeletions2(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.deletions(r, 2) })
+	benchmarkOp(b, func(trie *Trie, r []rune) { search(trie, r, 2, Deletions) })
 }
 
 func BenchmarkSubstitutions1(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.substitutions(r, 1) })
+	benchmarkOp(b, func(trie *Trie, r []rune) { search(trie, r, 1, Substitutions) })
 }
 
 func BenchmarkSubstitutions2(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.substitutions(r, 2) })
+	benchmarkOp(b, func(trie *Trie, r []rune) { search(trie, r, 2, Substitutions) })
 }
 
-func BenchmarkPermutations1(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.permutations(r, 1) })
+func BenchmarkTranspositions1(b *testing.B) {
+	benchmarkOp(b, func(trie *Trie, r []rune) { search(trie, r, 1, Transpositions) })
 }
 
-func BenchmarkPermutations2(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.permutations(r, 2) })
+func BenchmarkTranspositions2(b *testing.B) {
+	benchmarkOp(b, func(trie *Trie, r []rune) { search(trie, r, 2, Transpositions) })
+}
+
+func BenchmarkAnagrams(b *testing.B) {
+	benchmarkOp(b, func(trie *Trie, r []rune) { anagrams(trie, r, r) })
 }
 
 func BenchmarkSuggestions1(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.suggestions(r, 1) })
+	benchmarkOp(b, func(trie *Trie, r []rune) { suggest(trie, r, 1) })
 }
 
 func BenchmarkSuggestions2(b *testing.B) {
-	benchmarkOp(b, func(trie *Trie, r []rune) { trie.suggestions(r, 2) })
+	benchmarkOp(b, func(trie *Trie, r []rune) { suggest(trie, r, 2) })
+}
+
+func BenchmarkSuggestTopK2(b *testing.B) {
+	benchmarkOp(b, func(trie *Trie, r []rune) { suggestTopK(trie, r, 2, 5) })
 }
 
 func benchmarkOp(b *testing.B, op func(*Trie, []rune)) {
@@ -0,0 +1,246 @@
+package gospell
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// A DAWG is a minimal directed acyclic word graph: a Trie in which identical
+// subtrees, such as the common endings "-ing" and "-tion", are stored once.
+// It is built from a sorted word list with a DAWGBuilder and can't be
+// changed afterwards. It supports the same lookups and suggestions as a Trie.
+type DAWG struct {
+	lexicon[*dawgNode]
+}
+
+type dawgNode struct {
+	edges    []dawgEdge // Sorted by rune
+	leaf     bool
+	weight   int
+	heaviest int // The weight of the heaviest word under the node
+	id       int // Set once the node is minimized
+}
+
+type dawgEdge struct {
+	r  rune
+	to *dawgNode
+}
+
+// Words given to a DAWGBuilder must be in increasing order
+var ErrUnsorted = errors.New("words are not sorted")
+
+// A DAWGBuilder builds a DAWG from words inserted in sorted order, minimizing
+// the graph as it goes so that memory stays proportional to the final DAWG.
+type DAWGBuilder struct {
+	root     *dawgNode
+	previous string
+	// The path of the previous word, which may still be changed
+	unchecked []dawgEdge
+	minimized map[string]*dawgNode
+	finished  bool
+}
+
+// Create a new DAWGBuilder
+func NewDAWGBuilder() *DAWGBuilder {
+	return &DAWGBuilder{
+		root:      new(dawgNode),
+		minimized: make(map[string]*dawgNode),
+	}
+}
+
+// Insert a word, which must sort after every word inserted so far, as Go
+// compares strings. Inserting the previous word again does nothing.
+func (b *DAWGBuilder) Insert(word string) error {
+	return b.InsertWeighted(word, 0)
+}
+
+// Insert a word with a weight. See DAWGBuilder.Insert and Trie.InsertWeighted.
+// Only word endings with equal weights can be shared, so weights make the
+// DAWG larger.
+func (b *DAWGBuilder) InsertWeighted(word string, weight int) error {
+	if b.finished {
+		return errors.New("DAWGBuilder is finished")
+	}
+	if word == "" {
+		return errors.New("empty word")
+	}
+	if word == b.previous {
+		return nil
+	}
+	if word < b.previous {
+		return fmt.Errorf("%w: %q after %q", ErrUnsorted, word, b.previous)
+	}
+
+	r := runes(word)
+	common := 0
+	for _, c := range b.previous {
+		if common == len(r) || common == len(b.unchecked) || r[common] != c {
+			break
+		}
+		common++
+	}
+	b.minimize(common)
+
+	n := b.root
+	if common > 0 {
+		n = b.unchecked[common-1].to
+	}
+	for _, c := range r[common:] {
+		child := new(dawgNode)
+		n.edges = append(n.edges, dawgEdge{c, child})
+		b.unchecked = append(b.unchecked, dawgEdge{c, child})
+		n = child
+	}
+	n.leaf = true
+	n.weight = weight
+	b.previous = word
+	return nil
+}
+
+// Replace the unchecked nodes deeper than depth with equivalent minimized
+// nodes, or minimize them if there are none
+func (b *DAWGBuilder) minimize(depth int) {
+	for i := len(b.unchecked) - 1; i >= depth; i-- {
+		parent := b.root
+		if i > 0 {
+			parent = b.unchecked[i-1].to
+		}
+		child := b.unchecked[i].to
+		child.reweigh()
+		key := child.signature()
+		if existing, ok := b.minimized[key]; ok {
+			parent.edges[len(parent.edges)-1].to = existing
+		} else {
+			child.id = len(b.minimized) + 1
+			b.minimized[key] = child
+		}
+	}
+	b.unchecked = b.unchecked[:depth]
+}
+
+// Identify a node by its flags, weight and edges. Its children are already
+// minimized, so equal signatures mean equal subgraphs.
+func (n *dawgNode) signature() string {
+	key := []byte{0}
+	if n.leaf {
+		key[0] = 1
+	}
+	key = binary.AppendVarint(key, int64(n.weight))
+	for _, e := range n.edges {
+		key = binary.AppendUvarint(key, uint64(e.r))
+		key = binary.AppendUvarint(key, uint64(e.to.id))
+	}
+	return string(key)
+}
+
+// Finish building and return the DAWG. The builder can't be used afterwards.
+func (b *DAWGBuilder) Finish() *DAWG {
+	b.minimize(0)
+	b.root.reweigh()
+	b.finished = true
+	b.minimized = nil
+	return &DAWG{lexicon[*dawgNode]{b.root}}
+}
+
+// Load a sorted, newline-delimited list of words into a new DAWG. See
+// TrieFromFile for the line format.
+func DAWGFromFile(fname string) (*DAWG, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
+	}
+	defer f.Close()
+
+	return wordDAWG(f, fname)
+}
+
+// Load a sorted, newline-delimited list of words from an io.Reader into a new
+// DAWG. See TrieFromFile for the line format.
+func DAWGFromReader(r io.Reader) (*DAWG, error) {
+	return wordDAWG(r, "")
+}
+
+func wordDAWG(r io.Reader, name string) (*DAWG, error) {
+	b := NewDAWGBuilder()
+	err := scanLines(r, name, func(line int, text string) error {
+		text = strings.TrimSpace(text)
+		if err := b.Insert(text); err != nil {
+			return &ParseError{Name: name, Line: line, Text: text, Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Finish(), nil
+}
+
+// Compute the heaviest weight of a node whose children are minimized. Equal
+// signatures mean equal subgraphs, so it needn't be part of the signature.
+func (n *dawgNode) reweigh() {
+	n.heaviest = -infinity
+	if n.leaf {
+		n.heaviest = n.weight
+	}
+	for _, e := range n.edges {
+		n.heaviest = max(n.heaviest, e.to.heaviest)
+	}
+}
+
+func (n *dawgNode) heaviestWeight() int { return n.heaviest }
+
+func (n *dawgNode) terminal() (bool, int) { return n.leaf, n.weight }
+
+func (n *dawgNode) child(r rune) node {
+	i := sort.Search(len(n.edges), func(i int) bool { return n.edges[i].r >= r })
+	if i < len(n.edges) && n.edges[i].r == r {
+		return n.edges[i].to
+	}
+	return nil
+}
+
+func (n *dawgNode) each(fn func(rune, node) bool) bool {
+	for _, e := range n.edges {
+		if !fn(e.r, e.to) {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the DAWG at the end of a strings.Reader. It shares nodes with d.
+func (d *DAWG) Get(s *strings.Reader) *DAWG {
+	rest, _ := io.ReadAll(s)
+	n := get(d.root, string(rest))
+	if n == nil {
+		return nil
+	}
+	return &DAWG{lexicon[*dawgNode]{n.(*dawgNode)}}
+}
+
+// Write the DAWG to w in the flat format used by MappedTrie, keeping its
+// shared nodes shared. See Trie.WriteMapped.
+func (d *DAWG) WriteMapped(w io.Writer) (int64, error) {
+	return writeMapped(w, d.root)
+}
+
+// Count the distinct nodes in the DAWG
+func (d *DAWG) nodeCount() int {
+	seen := map[*dawgNode]bool{}
+	var count func(*dawgNode)
+	count = func(n *dawgNode) {
+		seen[n] = true
+		for _, e := range n.edges {
+			if !seen[e.to] {
+				count(e.to)
+			}
+		}
+	}
+	count(d.root)
+	return len(seen)
+}
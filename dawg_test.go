@@ -0,0 +1,140 @@
+package gospell
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDAWG(t *testing.T) {
+	words := []string{"talk", "talking", "walk", "walking", "walks"}
+	b := NewDAWGBuilder()
+	for _, w := range words {
+		if err := b.Insert(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Duplicates are skipped
+	if err := b.Insert("walks"); err != nil {
+		t.Error(err)
+	}
+	dawg := b.Finish()
+
+	if !HasEveryElement(t, dawg.AllFullChildren(), words) {
+		t.Error(dawg.AllFullChildren())
+	}
+	if !dawg.ContainsString("talking") || !dawg.Contains(strings.NewReader("walks")) {
+		t.Error("Inserted words should be Contained")
+	}
+	if dawg.ContainsString("talks") || dawg.ContainsString("walkin") {
+		t.Error("Shared endings shouldn't create new words")
+	}
+	// t and w lead to separate "alk" nodes, as only walk takes an s, but
+	// both share "ing" and every word shares its final node
+	if n := dawg.nodeCount(); n != 12 {
+		t.Errorf("Expected 12 nodes, found %d", n)
+	}
+	if !HasEveryElement(t, dawg.Get(strings.NewReader("walk")).AllFullChildren(), []string{"ing", "s"}) {
+		t.Error(dawg.Get(strings.NewReader("walk")).AllFullChildren())
+	}
+
+	suggestions := dawg.SuggestWords("talkin", 1)
+	if len(suggestions) != 1 || suggestions[0] != "talking" {
+		t.Errorf("Wrong suggestions %v", suggestions)
+	}
+}
+
+func TestDAWGErrors(t *testing.T) {
+	b := NewDAWGBuilder()
+	b.Insert("b")
+	if err := b.Insert("a"); !errors.Is(err, ErrUnsorted) {
+		t.Errorf("Expected ErrUnsorted, got %v", err)
+	}
+	if err := b.Insert(""); err == nil {
+		t.Error("Empty words should be rejected")
+	}
+
+	_, err := DAWGFromReader(strings.NewReader("apple\nbanana\napricot\n"))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 3 || !errors.Is(err, ErrUnsorted) {
+		t.Errorf("Expected ErrUnsorted on line 3, got %v", err)
+	}
+}
+
+// A DAWG must behave exactly like a Trie holding the same words
+func TestDAWGMatchesTrie(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	word := func() string {
+		w := make([]rune, 1+rng.Intn(8))
+		for i := range w {
+			w[i] = []rune("abcd狐犬")[rng.Intn(6)]
+		}
+		return string(w)
+	}
+
+	trie := NewTrie()
+	words := []string{}
+	for i := 0; i < 500; i++ {
+		w := word()
+		trie.InsertString(w)
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	dawg, err := DAWGFromReader(strings.NewReader(strings.Join(words, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !HasEveryElement(t, dawg.AllFullChildren(), trie.AllFullChildren()) {
+		t.Errorf("Expected %v, got %v", trie.AllFullChildren(), dawg.AllFullChildren())
+	}
+	if dawg.nodeCount() >= trie.Compact().nodeCount() {
+		t.Errorf("%d DAWG nodes isn't fewer than %d Radix nodes",
+			dawg.nodeCount(), trie.Compact().nodeCount())
+	}
+	for i := 0; i < 20; i++ {
+		s := word()
+		if dawg.ContainsString(s) != trie.ContainsString(s) {
+			t.Errorf("%q: DAWG and Trie disagree", s)
+		}
+		want := strings.Join(trie.SuggestWords(s, 2), ",")
+		if got := strings.Join(dawg.SuggestWords(s, 2), ","); got != want {
+			t.Errorf("SuggestWords(%q): expected %v, got %v", s, want, got)
+		}
+		want = strings.Join(trie.Additions(s, 2), ",")
+		if got := strings.Join(dawg.Additions(s, 2), ","); got != want {
+			t.Errorf("Additions(%q): expected %v, got %v", s, want, got)
+		}
+	}
+
+	// The flat format keeps shared nodes shared
+	var buf bytes.Buffer
+	if _, err := dawg.WriteMapped(&buf); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewMappedTrie(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.nodes)/mappedNodeSize != dawg.nodeCount() {
+		t.Errorf("Wrote %d nodes for %d", len(m.nodes)/mappedNodeSize, dawg.nodeCount())
+	}
+	if !HasEveryElement(t, m.AllFullChildren(), trie.AllFullChildren()) {
+		t.Error("The mapped DAWG lost words")
+	}
+}
+
+func BenchmarkLayoutDAWG(b *testing.B) {
+	benchmarkLayout(b, func(trie *Trie) interface{ nodeCount() int } {
+		words := trie.AllFullChildren()
+		sort.Strings(words)
+		builder := NewDAWGBuilder()
+		for _, w := range words {
+			builder.Insert(w)
+		}
+		return builder.Finish()
+	})
+}
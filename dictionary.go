@@ -0,0 +1,158 @@
+package gospell
+
+import (
+	"sort"
+	"sync"
+)
+
+// A Layer is one word list in a Dictionary
+type Layer struct {
+	// Recorded in the Matches suggested from this layer
+	Name string
+	// A Trie mustn't change while the Dictionary is in use, but a
+	// ConcurrentTrie may, such as a personal list learning new words
+	Words Lexicon
+	// Added to the weight of every word suggested from this layer, so that
+	// words from a glossary or personal list can be ranked above the rest
+	Boost int
+	// The words of an ignore layer are suppressed: the Dictionary never
+	// Contains or suggests them, whichever other layers hold them
+	Ignore bool
+}
+
+// A Dictionary combines several word lists, such as the system words, a
+// company glossary and a user's personal list, without merging them into one
+// Trie. A word is Contained if any layer holds it, and suggestions from every
+// layer are merged, remembering which layer each came from. A Dictionary is
+// safe for concurrent use, including AddLayer, as long as its layers are.
+type Dictionary struct {
+	mu     sync.RWMutex
+	layers []Layer // Replaced, never changed in place, by AddLayer
+}
+
+// Create a Dictionary of layers. Where several layers hold the same word,
+// the one giving it the highest weight wins, and the first of those on a tie.
+func NewDictionary(layers ...Layer) *Dictionary {
+	return &Dictionary{layers: append([]Layer{}, layers...)}
+}
+
+// Add a layer after the existing ones. Lookups and suggestions already
+// running don't see it.
+func (d *Dictionary) AddLayer(l Layer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.layers = append(d.layers[:len(d.layers):len(d.layers)], l)
+}
+
+// Get the current layers
+func (d *Dictionary) current() []Layer {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.layers
+}
+
+// Whether an ignore layer of layers holds s
+func ignored(layers []Layer, s string) bool {
+	for _, l := range layers {
+		if l.Ignore && contains(l.Words.rootNode(), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Find the layer holding s with the highest boosted weight
+func (d *Dictionary) lookup(s string) (layer *Layer, weight int) {
+	layers := d.current()
+	if ignored(layers, s) {
+		return nil, 0
+	}
+	for i, l := range layers {
+		if l.Ignore {
+			continue
+		}
+		n := get(l.Words.rootNode(), s)
+		if n == nil {
+			continue
+		}
+		if leaf, w := n.terminal(); leaf && (layer == nil || w+l.Boost > weight) {
+			layer, weight = &layers[i], w+l.Boost
+		}
+	}
+	return layer, weight
+}
+
+// Check if a String is Contained in any layer and not ignored
+func (d *Dictionary) ContainsString(s string) bool {
+	layer, _ := d.lookup(s)
+	return layer != nil
+}
+
+// Get the boosted weight of a word, or 0 if it isn't Contained
+func (d *Dictionary) Weight(s string) int {
+	_, weight := d.lookup(s)
+	return weight
+}
+
+// Get the name of the layer a word comes from, or "" if it isn't Contained
+func (d *Dictionary) Layer(s string) string {
+	layer, _ := d.lookup(s)
+	if layer == nil {
+		return ""
+	}
+	return layer.Name
+}
+
+// Return spelling suggestions for s configured by opts from every layer,
+// with boosted weights. Each word is suggested once, from the layer giving it
+// the highest weight, and words in an ignore layer are left out. The layers'
+// suggestions are merged before they are ranked by opts.Rank and cut to
+// opts.Limit, as a boost or ignore layer may change which are best. A
+// PhoneticIndex in opts.Phonetic only adds the words of the layer it indexes.
+func (d *Dictionary) Suggest(s string, opts SuggestOptions) Matches {
+	r := runes(s)
+	layerOpts := opts
+	layerOpts.Limit = 0
+	best := map[string]int{}
+	suggestions := Matches{}
+	layers := d.current()
+	for _, l := range layers {
+		if l.Ignore {
+			continue
+		}
+		for _, m := range suggestWith(l.Words.rootNode(), r, layerOpts) {
+			m.Weight += l.Boost
+			m.Layer = l.Name
+			word := string(m.Word)
+			if i, ok := best[word]; ok {
+				m.Phonetic = m.Phonetic || suggestions[i].Phonetic
+				if m.Weight > suggestions[i].Weight {
+					suggestions[i] = m
+				}
+				suggestions[i].Phonetic = m.Phonetic
+				continue
+			}
+			if ignored(layers, word) {
+				continue
+			}
+			best[word] = len(suggestions)
+			suggestions = append(suggestions, m)
+		}
+	}
+	sort.Sort(opts.rank(suggestions))
+	if opts.Limit > 0 && len(suggestions) > opts.Limit {
+		suggestions = suggestions[:opts.Limit]
+	}
+	return suggestions
+}
+
+// Suggest words within distance of s from every layer, ranked ByWeight. See
+// Dictionary.Suggest.
+func (d *Dictionary) Suggestions(s string, distance int) Matches {
+	return d.Suggest(s, SuggestOptions{Distance: distance})
+}
+
+// See Trie.SuggestWords and Dictionary.Suggest
+func (d *Dictionary) SuggestWords(s string, distance int) []string {
+	return d.Suggestions(s, distance).Strings()
+}
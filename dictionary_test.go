@@ -0,0 +1,136 @@
+package gospell
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestDictionary(t *testing.T) {
+	system := testTrie()
+	glossary := NewTrie()
+	glossary.InsertString("toadflax")
+	glossary.InsertStringWeighted("tod", 5)
+	personal := NewConcurrentTrie(nil)
+	personal.InsertString("toadie")
+	ignore := NewTrie()
+	ignore.InsertString("todd")
+
+	d := NewDictionary(
+		Layer{Name: "system", Words: system.Compact()},
+		Layer{Name: "glossary", Words: glossary, Boost: 100},
+		Layer{Name: "ignore", Words: ignore, Ignore: true},
+	)
+	d.AddLayer(Layer{Name: "personal", Words: personal})
+
+	for _, s := range []string{"toad", "toadflax", "toadie", "the"} {
+		if !d.ContainsString(s) {
+			t.Errorf("%q should be Contained", s)
+		}
+	}
+	if d.ContainsString("todd") || d.ContainsString("toadfl") {
+		t.Error("Ignored words and prefixes shouldn't be Contained")
+	}
+	if d.Layer("tod") != "glossary" || d.Weight("tod") != 105 {
+		t.Errorf("'tod' should come from the boosted glossary, got %q %d",
+			d.Layer("tod"), d.Weight("tod"))
+	}
+	if d.Layer("the") != "system" || d.Weight("the") != 500 || d.Layer("todd") != "" {
+		t.Error("Wrong layer for 'the' or 'todd'")
+	}
+
+	expected := Matches{
+		{Word: runes("toad"), Distance: 0, Layer: "system"},
+		{Word: runes("tod"), Distance: 1, Weight: 105, Layer: "glossary"},
+		{Word: runes("toada"), Distance: 1, Weight: 100, Layer: "glossary"},
+		{Word: runes("load"), Distance: 1, Layer: "system"},
+		{Word: runes("toads"), Distance: 1, Layer: "system"},
+		{Word: runes("toda"), Distance: 1, Layer: "system"},
+	}
+	glossary.InsertString("toada")
+	suggestions := d.Suggestions("toad", 1)
+	if len(suggestions) != len(expected) {
+		t.Fatalf("Wrong suggestions %v", suggestions)
+	}
+	for i := range expected {
+		if !suggestions[i].Equal(expected[i]) {
+			t.Errorf("Expected %v at %d, got %v", expected[i], i, suggestions[i])
+		}
+	}
+	words := d.SuggestWords("toadi", 1)
+	if !reflect.DeepEqual(words, []string{"toada", "toad", "toadie", "toads"}) {
+		t.Errorf("Wrong suggestions %v", words)
+	}
+}
+
+// Run with -race to check for data races
+func TestDictionaryConcurrentLayers(t *testing.T) {
+	personal := NewConcurrentTrie(nil)
+	d := NewDictionary(Layer{Name: "system", Words: testTrie().Snapshot()},
+		Layer{Name: "personal", Words: personal})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			personal.InsertString(fmt.Sprintf("toad%d", i))
+			d.AddLayer(Layer{Name: fmt.Sprint(i), Words: NewTrie().Snapshot()})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if len(d.SuggestWords("toad", 1)) == 0 || !d.ContainsString("toad") {
+				t.Error("'toad' should always be suggested and Contained")
+			}
+		}
+	}()
+	wg.Wait()
+
+	if d.Layer("toad49") != "personal" {
+		t.Errorf("Words learned by a ConcurrentTrie layer should be Contained")
+	}
+}
+
+func TestDictionarySuggest(t *testing.T) {
+	glossary := NewTrie()
+	glossary.InsertString("toed")
+	ignore := NewTrie()
+	ignore.InsertString("todd")
+	d := NewDictionary(
+		Layer{Name: "system", Words: testTrie().Snapshot()},
+		Layer{Name: "glossary", Words: glossary, Boost: 100},
+		Layer{Name: "ignore", Words: ignore, Ignore: true},
+	)
+	index := NewPhoneticIndex(glossary, DoubleMetaphoneEncoder)
+
+	tests := []struct {
+		s        string
+		opts     SuggestOptions
+		expected []string
+	}{
+		// The limit applies after boosting, so the glossary's word beats
+		// the system's words at the same distance
+		{"toad", SuggestOptions{Distance: 1, Limit: 2}, []string{"toad", "toed"}},
+		// and ignored words don't take up the limit
+		{"todds", SuggestOptions{Distance: 1, Limit: 1}, []string{"toads"}},
+		{"toad", SuggestOptions{Distance: 1, Limit: 2, Rank: func(m Matches) sort.Interface {
+			return ByDistance{m}
+		}}, []string{"toad", "load"}},
+		{"todx", SuggestOptions{Distance: 1, Edits: Deletions}, []string{"tod"}},
+		{"TOED", SuggestOptions{IgnoreCase: true}, []string{"toed"}},
+		{"tode", SuggestOptions{Phonetic: index}, []string{"toed"}},
+	}
+	for _, test := range tests {
+		suggestions := d.Suggest(test.s, test.opts)
+		if words := suggestions.Strings(); !reflect.DeepEqual(words, test.expected) {
+			t.Errorf("%q with %+v: got %v, expected %v", test.s, test.opts, words, test.expected)
+		}
+	}
+	if m := d.Suggest("tode", SuggestOptions{Phonetic: index})[0]; !m.Phonetic || m.Layer != "glossary" {
+		t.Errorf("'toed' should be suggested by sound from the glossary, got %+v", m)
+	}
+}
@@ -0,0 +1,569 @@
+package gospell
+
+import "strings"
+
+// Double Metaphone codes are at most this long
+const doubleMetaphoneLength = 4
+
+// Encode how a word sounds with Lawrence Philips' Double Metaphone algorithm,
+// which accounts for spellings from many languages besides English. The
+// primary code is the most likely pronunciation and the alternate another
+// common one, which is often the same.
+func DoubleMetaphone(word string) (primary, alternate string) {
+	d := &doubleMetaphone{word: []rune(strings.ToUpper(strings.TrimSpace(word)))}
+	if len(d.word) == 0 {
+		return "", ""
+	}
+	d.slavoGermanic = d.has("W") || d.has("K") || d.has("CZ") || d.has("WITZ")
+
+	i := 0
+	if d.at(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		// Skip the silent first letter
+		i = 1
+	}
+	for i < len(d.word) && !d.complete() {
+		i = d.next(i)
+	}
+	return d.primary.String(), d.alternate.String()
+}
+
+type doubleMetaphone struct {
+	word               []rune
+	slavoGermanic      bool
+	primary, alternate strings.Builder
+}
+
+func (d *doubleMetaphone) has(s string) bool {
+	return strings.Contains(string(d.word), s)
+}
+
+// The rune at i, or 0 outside the word
+func (d *doubleMetaphone) char(i int) rune {
+	if i < 0 || i >= len(d.word) {
+		return 0
+	}
+	return d.word[i]
+}
+
+// Whether the n runes at i are any of options
+func (d *doubleMetaphone) at(i, n int, options ...string) bool {
+	if i < 0 || i+n > len(d.word) {
+		return false
+	}
+	s := string(d.word[i : i+n])
+	for _, o := range options {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *doubleMetaphone) vowel(i int) bool {
+	return strings.ContainsRune("AEIOUY", d.char(i))
+}
+
+func (d *doubleMetaphone) complete() bool {
+	return d.primary.Len() >= doubleMetaphoneLength && d.alternate.Len() >= doubleMetaphoneLength
+}
+
+func appendCode(b *strings.Builder, s string) {
+	if room := doubleMetaphoneLength - b.Len(); room > 0 {
+		b.WriteString(s[:min(len(s), room)])
+	}
+}
+
+// Add to both codes
+func (d *doubleMetaphone) add(s string) {
+	d.addBoth(s, s)
+}
+
+func (d *doubleMetaphone) addBoth(primary, alternate string) {
+	appendCode(&d.primary, primary)
+	appendCode(&d.alternate, alternate)
+}
+
+// Encode the sound starting at i, returning where the next one starts
+func (d *doubleMetaphone) next(i int) int {
+	// Skip a doubled letter if the rune at i+1 is c
+	skip := func(c rune) int {
+		if d.char(i+1) == c {
+			return i + 2
+		}
+		return i + 1
+	}
+
+	switch c := d.char(i); c {
+	case 'A', 'E', 'I', 'O', 'U', 'Y':
+		// Vowels only count at the start
+		if i == 0 {
+			d.add("A")
+		}
+		return i + 1
+	case 'B':
+		d.add("P")
+		return skip('B')
+	case 'Ç':
+		d.add("S")
+		return i + 1
+	case 'C':
+		return d.c(i)
+	case 'D':
+		return d.d(i)
+	case 'F':
+		d.add("F")
+		return skip('F')
+	case 'G':
+		return d.g(i)
+	case 'H':
+		// Only kept first or between vowels
+		if (i == 0 || d.vowel(i-1)) && d.vowel(i+1) {
+			d.add("H")
+			return i + 2
+		}
+		return i + 1
+	case 'J':
+		return d.j(i)
+	case 'K':
+		d.add("K")
+		return skip('K')
+	case 'L':
+		if d.char(i+1) == 'L' {
+			if d.spanishLL(i) {
+				d.addBoth("L", "")
+			} else {
+				d.add("L")
+			}
+			return i + 2
+		}
+		d.add("L")
+		return i + 1
+	case 'M':
+		d.add("M")
+		if d.char(i+1) == 'M' || d.at(i-1, 3, "UMB") &&
+			(i+1 == len(d.word)-1 || d.at(i+2, 2, "ER")) {
+			return i + 2
+		}
+		return i + 1
+	case 'N':
+		d.add("N")
+		return skip('N')
+	case 'Ñ':
+		d.add("N")
+		return i + 1
+	case 'P':
+		if d.char(i+1) == 'H' {
+			d.add("F")
+			return i + 2
+		}
+		d.add("P")
+		if d.at(i+1, 1, "P", "B") {
+			return i + 2
+		}
+		return i + 1
+	case 'Q':
+		d.add("K")
+		return skip('Q')
+	case 'R':
+		// French, e.g. "Rogier"
+		if i == len(d.word)-1 && !d.slavoGermanic && d.at(i-2, 2, "IE") &&
+			!d.at(i-4, 2, "ME", "MA") {
+			d.addBoth("", "R")
+		} else {
+			d.add("R")
+		}
+		return skip('R')
+	case 'S':
+		return d.s(i)
+	case 'T':
+		return d.t(i)
+	case 'V':
+		d.add("F")
+		return skip('V')
+	case 'W':
+		return d.w(i)
+	case 'X':
+		return d.x(i)
+	case 'Z':
+		return d.z(i)
+	}
+	return i + 1
+}
+
+func (d *doubleMetaphone) c(i int) int {
+	switch {
+	case d.germanicC(i):
+		d.add("K")
+		return i + 2
+	case i == 0 && d.at(i, 6, "CAESAR"):
+		d.add("S")
+		return i + 2
+	case d.at(i, 2, "CH"):
+		return d.ch(i)
+	case d.at(i, 2, "CZ") && !d.at(i-2, 4, "WICZ"):
+		// e.g. "Czerny"
+		d.addBoth("S", "X")
+		return i + 2
+	case d.at(i+1, 3, "CIA"):
+		// e.g. "focaccia"
+		d.add("X")
+		return i + 3
+	case d.at(i, 2, "CC") && !(i == 1 && d.char(0) == 'M'):
+		// Double C, but not "McClellan"
+		if d.at(i+2, 1, "I", "E", "H") && !d.at(i+2, 2, "HU") {
+			if i == 1 && d.char(i-1) == 'A' || d.at(i-1, 5, "UCCEE", "UCCES") {
+				// e.g. "accident", "success"
+				d.add("KS")
+			} else {
+				// e.g. "bacci", "bertucci"
+				d.add("X")
+			}
+			return i + 3
+		}
+		// e.g. "Pierce's rule"
+		d.add("K")
+		return i + 2
+	case d.at(i, 2, "CK", "CG", "CQ"):
+		d.add("K")
+		return i + 2
+	case d.at(i, 2, "CI", "CE", "CY"):
+		// Italian or not
+		if d.at(i, 3, "CIO", "CIE", "CIA") {
+			d.addBoth("S", "X")
+		} else {
+			d.add("S")
+		}
+		return i + 2
+	}
+	d.add("K")
+	switch {
+	case d.at(i+1, 2, " C", " Q", " G"):
+		// e.g. "Mac Caffrey", "Mac Gregor"
+		return i + 3
+	case d.at(i+1, 1, "C", "K", "Q") && !d.at(i+1, 2, "CE", "CI"):
+		return i + 2
+	}
+	return i + 1
+}
+
+// Whether C is hard as in Germanic "-ach-", but not "bacher" or "macher"
+func (d *doubleMetaphone) germanicC(i int) bool {
+	switch {
+	case d.at(i, 4, "CHIA"):
+		return true
+	case i <= 1 || d.vowel(i-2) || !d.at(i-1, 3, "ACH"):
+		return false
+	}
+	c := d.char(i + 2)
+	return c != 'I' && c != 'E' || d.at(i-2, 6, "BACHER", "MACHER")
+}
+
+func (d *doubleMetaphone) ch(i int) int {
+	switch {
+	case i > 0 && d.at(i, 4, "CHAE"):
+		// e.g. "Michael"
+		d.addBoth("K", "X")
+	case d.greekCH(i), d.kCH(i):
+		d.add("K")
+	case i == 0:
+		d.add("X")
+	case d.at(0, 2, "MC"):
+		// e.g. "McHugh"
+		d.add("K")
+	default:
+		d.addBoth("X", "K")
+	}
+	return i + 2
+}
+
+// Whether CH starts a word with a Greek root, e.g. "chemistry", "chorus"
+func (d *doubleMetaphone) greekCH(i int) bool {
+	return i == 0 && (d.at(i+1, 5, "HARAC", "HARIS") ||
+		d.at(i+1, 3, "HOR", "HYM", "HIA", "HEM")) && !d.at(0, 5, "CHORE")
+}
+
+// Whether CH is pronounced K, as in Germanic names, "orchestra" or
+// "Christopher"
+func (d *doubleMetaphone) kCH(i int) bool {
+	return d.at(0, 4, "VAN ", "VON ") || d.at(0, 3, "SCH") ||
+		d.at(i-2, 6, "ORCHES", "ARCHIT", "ORCHID") ||
+		d.at(i+2, 1, "T", "S") ||
+		(d.at(i-1, 1, "A", "O", "U", "E") || i == 0) &&
+			(d.at(i+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") ||
+				i+1 == len(d.word)-1)
+}
+
+func (d *doubleMetaphone) d(i int) int {
+	switch {
+	case d.at(i, 2, "DG"):
+		if d.at(i+2, 1, "I", "E", "Y") {
+			// e.g. "edge"
+			d.add("J")
+			return i + 3
+		}
+		// e.g. "Edgar"
+		d.add("TK")
+		return i + 2
+	case d.at(i, 2, "DT", "DD"):
+		d.add("T")
+		return i + 2
+	}
+	d.add("T")
+	return i + 1
+}
+
+func (d *doubleMetaphone) g(i int) int {
+	switch {
+	case d.char(i+1) == 'H':
+		return d.gh(i)
+	case d.char(i+1) == 'N':
+		switch {
+		case i == 1 && d.vowel(0) && !d.slavoGermanic:
+			d.addBoth("KN", "N")
+		case !d.at(i+2, 2, "EY") && !d.slavoGermanic:
+			// Not e.g. "Cagney"
+			d.addBoth("N", "KN")
+		default:
+			d.add("KN")
+		}
+		return i + 2
+	case d.at(i+1, 2, "LI") && !d.slavoGermanic:
+		// e.g. "Tagliaro"
+		d.addBoth("KL", "L")
+		return i + 2
+	case i == 0 && (d.char(i+1) == 'Y' ||
+		d.at(i+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		// e.g. "Gerber"
+		d.addBoth("K", "J")
+		return i + 2
+	case (d.at(i+1, 2, "ER") || d.char(i+1) == 'Y') &&
+		!d.at(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!d.at(i-1, 1, "E", "I") && !d.at(i-1, 3, "RGY", "OGY"):
+		// e.g. "Hunger", but not "danger"
+		d.addBoth("K", "J")
+		return i + 2
+	case d.at(i+1, 1, "E", "I", "Y") || d.at(i-1, 4, "AGGI", "OGGI"):
+		// Italian, e.g. "Biaggi"
+		switch {
+		case d.at(0, 4, "VAN ", "VON ") || d.at(0, 3, "SCH") || d.at(i+1, 2, "ET"):
+			// Germanic
+			d.add("K")
+		case d.at(i+1, 3, "IER"):
+			d.add("J")
+		default:
+			d.addBoth("J", "K")
+		}
+		return i + 2
+	}
+	d.add("K")
+	if d.char(i+1) == 'G' {
+		return i + 2
+	}
+	return i + 1
+}
+
+func (d *doubleMetaphone) gh(i int) int {
+	switch {
+	case i > 0 && !d.vowel(i-1):
+		d.add("K")
+	case i == 0:
+		// e.g. "ghislane", "ghiradelli"
+		if d.char(i+2) == 'I' {
+			d.add("J")
+		} else {
+			d.add("K")
+		}
+	case i > 1 && d.at(i-2, 1, "B", "H", "D") ||
+		i > 2 && d.at(i-3, 1, "B", "H", "D") ||
+		i > 3 && d.at(i-4, 1, "B", "H"):
+		// Parker's rule, e.g. "Hugh", "bough", "broughton"
+	case i > 2 && d.char(i-1) == 'U' && d.at(i-3, 1, "C", "G", "L", "R", "T"):
+		// e.g. "laugh", "McLaughlin", "cough", "rough"
+		d.add("F")
+	case d.char(i-1) != 'I':
+		d.add("K")
+	}
+	return i + 2
+}
+
+func (d *doubleMetaphone) j(i int) int {
+	if d.at(i, 4, "JOSE") || d.at(0, 4, "SAN ") {
+		// Spanish, e.g. "Jose", "San Jacinto"
+		if i == 0 && (d.char(i+4) == ' ' || len(d.word) == 4) || d.at(0, 4, "SAN ") {
+			d.add("H")
+		} else {
+			d.addBoth("J", "H")
+		}
+		return i + 1
+	}
+	switch {
+	case i == 0:
+		// e.g. "Yankelovich", "Jankelowicz"
+		d.addBoth("J", "A")
+	case d.vowel(i-1) && !d.slavoGermanic && (d.char(i+1) == 'A' || d.char(i+1) == 'O'):
+		// Spanish, e.g. "bajador"
+		d.addBoth("J", "H")
+	case i == len(d.word)-1:
+		d.addBoth("J", "")
+	case !d.at(i+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !d.at(i-1, 1, "S", "K", "L"):
+		d.add("J")
+	}
+	if d.char(i+1) == 'J' {
+		return i + 2
+	}
+	return i + 1
+}
+
+// Whether LL is Spanish, e.g. "cabrillo", "gallegos"
+func (d *doubleMetaphone) spanishLL(i int) bool {
+	n := len(d.word)
+	return i == n-3 && d.at(i-1, 4, "ILLO", "ILLA", "ALLE") ||
+		(d.at(n-2, 2, "AS", "OS") || d.at(n-1, 1, "A", "O")) && d.at(i-1, 4, "ALLE")
+}
+
+func (d *doubleMetaphone) s(i int) int {
+	switch {
+	case d.at(i-1, 3, "ISL", "YSL"):
+		// Silent, e.g. "island", "isle", "carlisle"
+		return i + 1
+	case i == 0 && d.at(i, 5, "SUGAR"):
+		d.addBoth("X", "S")
+		return i + 1
+	case d.at(i, 2, "SH"):
+		// Germanic
+		if d.at(i+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			d.add("S")
+		} else {
+			d.add("X")
+		}
+		return i + 2
+	case d.at(i, 3, "SIO", "SIA") || d.at(i, 4, "SIAN"):
+		// Italian and Armenian
+		if d.slavoGermanic {
+			d.add("S")
+		} else {
+			d.addBoth("S", "X")
+		}
+		return i + 3
+	case i == 0 && d.at(i+1, 1, "M", "N", "L", "W") || d.at(i+1, 1, "Z"):
+		// German and Anglicisations, e.g. "Smith" and "Schmidt", "snider"
+		// and "Schneider"
+		d.addBoth("S", "X")
+		if d.at(i+1, 1, "Z") {
+			return i + 2
+		}
+		return i + 1
+	case d.at(i, 2, "SC"):
+		return d.sc(i)
+	}
+	// French, e.g. "resnais", "artois"
+	if i == len(d.word)-1 && d.at(i-2, 2, "AI", "OI") {
+		d.addBoth("", "S")
+	} else {
+		d.add("S")
+	}
+	if d.at(i+1, 1, "S", "Z") {
+		return i + 2
+	}
+	return i + 1
+}
+
+func (d *doubleMetaphone) sc(i int) int {
+	switch {
+	case d.char(i+2) == 'H':
+		switch {
+		case d.at(i+3, 2, "ER", "EN"):
+			// e.g. "schermerhorn", "schenker"
+			d.addBoth("X", "SK")
+		case d.at(i+3, 2, "OO", "UY", "ED", "EM"):
+			// Dutch, e.g. "school", "schooner"
+			d.add("SK")
+		case i == 0 && !d.vowel(3) && d.char(3) != 'W':
+			d.addBoth("X", "S")
+		default:
+			d.add("X")
+		}
+	case d.at(i+2, 1, "I", "E", "Y"):
+		d.add("S")
+	default:
+		d.add("SK")
+	}
+	return i + 3
+}
+
+func (d *doubleMetaphone) t(i int) int {
+	switch {
+	case d.at(i, 4, "TION"), d.at(i, 3, "TIA", "TCH"):
+		d.add("X")
+		return i + 3
+	case d.at(i, 2, "TH") || d.at(i, 3, "TTH"):
+		// e.g. "Thomas", "Thames"
+		if d.at(i+2, 2, "OM", "AM") || d.at(0, 4, "VAN ", "VON ") || d.at(0, 3, "SCH") {
+			d.add("T")
+		} else {
+			d.addBoth("0", "T")
+		}
+		return i + 2
+	}
+	d.add("T")
+	if d.at(i+1, 1, "T", "D") {
+		return i + 2
+	}
+	return i + 1
+}
+
+func (d *doubleMetaphone) w(i int) int {
+	switch {
+	case d.at(i, 2, "WR"):
+		d.add("R")
+		return i + 2
+	case i == 0 && (d.vowel(i+1) || d.at(i, 2, "WH")):
+		// e.g. "Wasserman" should match "Vasserman"
+		if d.vowel(i + 1) {
+			d.addBoth("A", "F")
+		} else {
+			d.add("A")
+		}
+	case i == len(d.word)-1 && d.vowel(i-1) ||
+		d.at(i-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || d.at(0, 3, "SCH"):
+		// Polish, e.g. "Filipowicz"
+		d.addBoth("", "F")
+	case d.at(i, 4, "WICZ", "WITZ"):
+		d.addBoth("TS", "FX")
+		return i + 4
+	}
+	return i + 1
+}
+
+func (d *doubleMetaphone) x(i int) int {
+	if i == 0 {
+		// e.g. "Xavier"
+		d.add("S")
+		return i + 1
+	}
+	// French, e.g. "breaux"
+	if !(i == len(d.word)-1 && (d.at(i-3, 3, "IAU", "EAU") || d.at(i-2, 2, "AU", "OU"))) {
+		d.add("KS")
+	}
+	if d.at(i+1, 1, "C", "X") {
+		return i + 2
+	}
+	return i + 1
+}
+
+func (d *doubleMetaphone) z(i int) int {
+	if d.char(i+1) == 'H' {
+		// Chinese pinyin, e.g. "Zhao"
+		d.add("J")
+		return i + 2
+	}
+	if d.at(i+1, 2, "ZO", "ZI", "ZA") || d.slavoGermanic && i > 0 && d.char(i-1) != 'T' {
+		d.addBoth("S", "TS")
+	} else {
+		d.add("S")
+	}
+	if d.char(i+1) == 'Z' {
+		return i + 2
+	}
+	return i + 1
+}
@@ -0,0 +1,29 @@
+package gospell
+
+import "testing"
+
+func TestDoubleMetaphone(t *testing.T) {
+	tests := []struct {
+		word, primary, alternate string
+	}{
+		{"Smith", "SM0", "XMT"},
+		{"Schmidt", "XMT", "SMT"},
+		{"Michael", "MKL", "MXL"},
+		{"Xavier", "SF", "SFR"},
+		{"Caesar", "SSR", "SSR"},
+		{"Jose", "HS", "HS"},
+		{"Wasserman", "ASRM", "FSRM"},
+		{"knight", "NT", "NT"},
+		{"dumb", "TM", "TM"},
+		{"physics", "FSKS", "FSKS"},
+		{"fizzix", "FSKS", "FTSK"},
+		{"", "", ""},
+	}
+	for _, test := range tests {
+		primary, alternate := DoubleMetaphone(test.word)
+		if primary != test.primary || alternate != test.alternate {
+			t.Errorf("%q should encode as %v/%v, got %v/%v",
+				test.word, test.primary, test.alternate, primary, alternate)
+		}
+	}
+}
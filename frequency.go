@@ -0,0 +1,130 @@
+package gospell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// A FrequencyFormat describes the columns of a word frequency list
+type FrequencyFormat struct {
+	// Separator between columns. If empty, columns are separated by runs of
+	// whitespace and leading whitespace is ignored.
+	Separator string
+	// Zero-based column holding the word
+	WordColumn int
+	// Zero-based column holding the word's count
+	CountColumn int
+}
+
+var (
+	// "word<TAB>count", as written by most corpus and n-gram tools
+	WordTabCount = FrequencyFormat{Separator: "\t", WordColumn: 0, CountColumn: 1}
+	// "count word", as written by `sort | uniq -c`
+	CountSpaceWord = FrequencyFormat{WordColumn: 1, CountColumn: 0}
+)
+
+// A ParseError reports a malformed line in a dictionary
+type ParseError struct {
+	Name string // The file being read, or "" for an io.Reader
+	Line int    // One-based line number
+	Text string // The offending line
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("line %d: %v: %q", e.Line, e.Err, e.Text)
+	}
+	return fmt.Sprintf("%v:%d: %v: %q", e.Name, e.Line, e.Err, e.Text)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Load a word frequency list into a new Trie, using each word's count as its
+// weight. Blank lines and lines starting with '#' are skipped; any other line
+// that doesn't match the format, including one with more columns than the
+// format's word and count, is reported as a *ParseError. Whitespace around
+// words and counts is ignored.
+func TrieFromFrequencyFile(fname string, format FrequencyFormat) (*Trie, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
+	}
+	defer f.Close()
+
+	return frequencyTrie(f, fname, format)
+}
+
+// Load a word frequency list from a file in fsys, such as an embed.FS, into a
+// new Trie. See TrieFromFrequencyFile.
+func TrieFromFrequencyFS(fsys fs.FS, name string, format FrequencyFormat) (*Trie, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
+	}
+	defer f.Close()
+
+	return frequencyTrie(f, name, format)
+}
+
+// Load a word frequency list from an io.Reader into a new Trie. See
+// TrieFromFrequencyFile.
+func TrieFromFrequencyReader(r io.Reader, format FrequencyFormat) (*Trie, error) {
+	return frequencyTrie(r, "", format)
+}
+
+func frequencyTrie(r io.Reader, name string, format FrequencyFormat) (*Trie, error) {
+	trie := NewTrie()
+	if err := trie.insertFrequencies(r, name, format); err != nil {
+		return nil, err
+	}
+	return trie, nil
+}
+
+// Insert every "word, count" line of r into the Trie
+func (t *Trie) insertFrequencies(r io.Reader, name string, format FrequencyFormat) error {
+	if format.WordColumn < 0 || format.CountColumn < 0 ||
+		format.WordColumn == format.CountColumn {
+		return fmt.Errorf("invalid frequency format %+v", format)
+	}
+
+	return scanLines(r, name, func(line int, text string) error {
+		word, count, err := format.parse(text)
+		if err != nil {
+			return &ParseError{Name: name, Line: line, Text: text, Err: err}
+		}
+		t.InsertStringWeighted(word, count)
+		return nil
+	})
+}
+
+// Split a line into its word and count
+func (format FrequencyFormat) parse(text string) (string, int, error) {
+	var columns []string
+	if format.Separator == "" {
+		columns = strings.Fields(text)
+	} else {
+		columns = strings.Split(text, format.Separator)
+	}
+
+	// Extra columns are most likely a word split at whitespace, such as
+	// "12 ice cream", so aren't ignored
+	needed := max(format.WordColumn, format.CountColumn) + 1
+	if len(columns) != needed {
+		return "", 0, fmt.Errorf("expected %d columns, found %d", needed, len(columns))
+	}
+	word := strings.TrimSpace(columns[format.WordColumn])
+	if word == "" {
+		return "", 0, errors.New("empty word")
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(columns[format.CountColumn]))
+	if err != nil {
+		return "", 0, fmt.Errorf("bad count: %w", err)
+	}
+	return word, count, nil
+}
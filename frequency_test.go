@@ -0,0 +1,113 @@
+package gospell
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func writeFile(t *testing.T, contents string) string {
+	fname := filepath.Join(t.TempDir(), "words")
+	if err := os.WriteFile(fname, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fname
+}
+
+func TestTrieFromFrequencyFile(t *testing.T) {
+	tests := []struct {
+		format   FrequencyFormat
+		contents string
+	}{
+		{WordTabCount, "the\t500\ntea\t10\r\n\nteh\t1"},
+		{CountSpaceWord, "    500 the\n     10 tea\n\n      1 teh\n"},
+		{FrequencyFormat{Separator: ",", WordColumn: 2, CountColumn: 0},
+			"500,x,the\n10,y,tea\n1,z,teh\n"},
+		{FrequencyFormat{Separator: ",", WordColumn: 1, CountColumn: 0},
+			"500, the\n10 ,tea \n1,teh\n"},
+	}
+
+	for _, test := range tests {
+		trie, err := TrieFromFrequencyFile(writeFile(t, test.contents), test.format)
+		if err != nil {
+			t.Errorf("%+v: %v", test.format, err)
+			continue
+		}
+		for word, weight := range map[string]int{"the": 500, "tea": 10, "teh": 1} {
+			if !trie.ContainsString(word) || trie.Weight(word) != weight {
+				t.Errorf("%+v: %q should have weight %d, got %d",
+					test.format, word, weight, trie.Weight(word))
+			}
+		}
+		suggestions := trie.SuggestWords("teh", 1)
+		if len(suggestions) != 3 || suggestions[1] != "the" {
+			t.Errorf("%+v: wrong suggestions %v", test.format, suggestions)
+		}
+	}
+}
+
+func TestTrieFromFrequencyFileErrors(t *testing.T) {
+	tests := []struct {
+		format   FrequencyFormat
+		contents string
+		line     int
+		err      error
+	}{
+		{WordTabCount, "the\t500\ntea\n", 2, nil},
+		{WordTabCount, "the\t500\n\ntea\tmany\n", 3, strconv.ErrSyntax},
+		{WordTabCount, "\t500\n", 1, nil},
+		{WordTabCount, " \t500\n", 1, nil},
+		{WordTabCount, "the\t500\ntea\t10\tnoun\n", 2, nil},
+		// A word split at whitespace isn't truncated to its first part
+		{CountSpaceWord, "500 the\n12 ice cream\n", 2, nil},
+	}
+
+	for _, test := range tests {
+		_, err := TrieFromFrequencyFile(writeFile(t, test.contents), test.format)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: expected a ParseError, got %v", test.contents, err)
+			continue
+		}
+		if perr.Line != test.line {
+			t.Errorf("%q: error on line %d, expected %d", test.contents, perr.Line, test.line)
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%q: %v should wrap %v", test.contents, err, test.err)
+		}
+	}
+
+	_, err := TrieFromFrequencyFile(filepath.Join(t.TempDir(), "missing"), WordTabCount)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Missing file should be reported, got %v", err)
+	}
+}
+
+func TestTrieFromFrequencyReader(t *testing.T) {
+	counts := "the\t500\ntea\t10\n"
+	trie, err := TrieFromFrequencyReader(strings.NewReader(counts), WordTabCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trie.Weight("the") != 500 || trie.Weight("tea") != 10 {
+		t.Errorf("Wrong weights %d %d", trie.Weight("the"), trie.Weight("tea"))
+	}
+
+	fsys := fstest.MapFS{"counts.tsv": &fstest.MapFile{Data: []byte(counts)}}
+	trie, err = TrieFromFrequencyFS(fsys, "counts.tsv", WordTabCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trie.Weight("the") != 500 || trie.Weight("tea") != 10 {
+		t.Errorf("Wrong weights %d %d", trie.Weight("the"), trie.Weight("tea"))
+	}
+
+	_, err = TrieFromFrequencyReader(strings.NewReader("the\n"), WordTabCount)
+	if err == nil || !strings.HasPrefix(err.Error(), "line 1:") {
+		t.Errorf("Expected an error on line 1, got %v", err)
+	}
+}
@@ -0,0 +1,520 @@
+package gospell
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Load a Hunspell dictionary into a new Trie. The prefix and suffix rules of
+// the .aff file are applied to every stem in the .dic file, so the Trie holds
+// each inflected form as well as the stems. Compounding and morphological
+// fields are ignored.
+func TrieFromHunspell(affName, dicName string) (*Trie, error) {
+	aff, err := os.Open(affName)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
+	}
+	defer aff.Close()
+	dic, err := os.Open(dicName)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
+	}
+	defer dic.Close()
+
+	return hunspellTrie(aff, affName, dic, dicName)
+}
+
+// Load a Hunspell dictionary from files in fsys into a new Trie. See
+// TrieFromHunspell.
+func TrieFromHunspellFS(fsys fs.FS, affName, dicName string) (*Trie, error) {
+	aff, err := fsys.Open(affName)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
+	}
+	defer aff.Close()
+	dic, err := fsys.Open(dicName)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
+	}
+	defer dic.Close()
+
+	return hunspellTrie(aff, affName, dic, dicName)
+}
+
+// Load a Hunspell dictionary from the contents of its .aff and .dic files into
+// a new Trie. See TrieFromHunspell.
+func TrieFromHunspellReader(aff, dic io.Reader) (*Trie, error) {
+	return hunspellTrie(aff, "", dic, "")
+}
+
+func hunspellTrie(aff io.Reader, affName string, dic io.Reader, dicName string) (*Trie, error) {
+	rules, err := readAffixes(aff, affName)
+	if err != nil {
+		return nil, err
+	}
+	trie := NewTrie()
+	if err := rules.insertStems(trie, dic, dicName); err != nil {
+		return nil, err
+	}
+	return trie, nil
+}
+
+// The affix rules and options of a Hunspell .aff file
+type affixes struct {
+	charset   string
+	flagType  string
+	aliases   [][]string
+	prefixes  map[string]*affixClass
+	suffixes  map[string]*affixClass
+	needAffix string
+	forbidden string
+	compound  string // ONLYINCOMPOUND
+}
+
+// All the rules sharing a PFX or SFX flag
+type affixClass struct {
+	prefix bool
+	cross  bool
+	rules  []affixRule
+}
+
+type affixRule struct {
+	strip     []rune
+	add       []rune
+	flags     []string
+	condition []condition
+}
+
+// One position of an affix condition: a rune, a [class] or '.'
+type condition struct {
+	runes  []rune
+	negate bool
+	any    bool
+}
+
+func (c condition) matches(r rune) bool {
+	if c.any {
+		return true
+	}
+	for _, cr := range c.runes {
+		if cr == r {
+			return !c.negate
+		}
+	}
+	return c.negate
+}
+
+func readAffixes(r io.Reader, name string) (*affixes, error) {
+	text, charset, err := decodeHunspell(r, "")
+	if err != nil {
+		return nil, fmt.Errorf("reading %v: %w", name, err)
+	}
+	a := &affixes{
+		charset:  charset,
+		prefixes: make(map[string]*affixClass),
+		suffixes: make(map[string]*affixClass),
+	}
+
+	err = scanLines(strings.NewReader(text), name, func(line int, text string) error {
+		if err := a.parse(strings.Fields(text)); err != nil {
+			return &ParseError{Name: name, Line: line, Text: text, Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Parse one line of an .aff file
+func (a *affixes) parse(fields []string) error {
+	switch fields[0] {
+	case "FLAG":
+		if len(fields) < 2 {
+			return errors.New("missing flag type")
+		}
+		switch fields[1] {
+		case "long", "num", "UTF-8":
+			a.flagType = fields[1]
+		default:
+			return fmt.Errorf("unknown flag type %v", fields[1])
+		}
+	case "AF":
+		if len(fields) < 2 {
+			return errors.New("missing flag alias")
+		}
+		// The first AF line gives the number of aliases that follow
+		if _, err := strconv.Atoi(fields[1]); err == nil && a.aliases == nil {
+			a.aliases = [][]string{}
+			return nil
+		}
+		a.aliases = append(a.aliases, a.splitFlags(fields[1]))
+	case "NEEDAFFIX", "PSEUDOROOT":
+		if len(fields) > 1 {
+			a.needAffix = fields[1]
+		}
+	case "FORBIDDENWORD":
+		if len(fields) > 1 {
+			a.forbidden = fields[1]
+		}
+	case "ONLYINCOMPOUND":
+		if len(fields) > 1 {
+			a.compound = fields[1]
+		}
+	case "PFX", "SFX":
+		return a.parseAffix(fields)
+	}
+	return nil
+}
+
+// Parse a PFX or SFX line, which is either the header of a class:
+//
+//	SFX flag cross_product number_of_rules
+//
+// or one of its rules:
+//
+//	SFX flag stripping_characters affix[/flags] [condition [morphology]]
+func (a *affixes) parseAffix(fields []string) error {
+	if len(fields) < 4 {
+		return errors.New("too few fields")
+	}
+	prefix := fields[0] == "PFX"
+	classes := a.suffixes
+	if prefix {
+		classes = a.prefixes
+	}
+	flag := fields[1]
+	class, ok := classes[flag]
+	if !ok {
+		if fields[2] != "Y" && fields[2] != "N" {
+			return fmt.Errorf("expected Y or N for the cross product, found %v", fields[2])
+		}
+		if _, err := strconv.Atoi(fields[3]); err != nil {
+			return fmt.Errorf("bad rule count: %w", err)
+		}
+		classes[flag] = &affixClass{prefix: prefix, cross: fields[2] == "Y"}
+		return nil
+	}
+
+	rule := affixRule{}
+	if fields[2] != "0" {
+		rule.strip = []rune(fields[2])
+	}
+	add, flags, _ := strings.Cut(fields[3], "/")
+	if add != "0" {
+		rule.add = []rune(add)
+	}
+	if flags != "" {
+		rule.flags = a.expandFlags(flags)
+	}
+	cond := "."
+	if len(fields) > 4 {
+		cond = fields[4]
+	}
+	var err error
+	if rule.condition, err = parseCondition(cond); err != nil {
+		return err
+	}
+	class.rules = append(class.rules, rule)
+	return nil
+}
+
+func parseCondition(s string) ([]condition, error) {
+	conds := []condition{}
+	r := []rune(s)
+	for i := 0; i < len(r); i++ {
+		switch r[i] {
+		case '.':
+			conds = append(conds, condition{any: true})
+		case '[':
+			end := i + 1
+			for end < len(r) && r[end] != ']' {
+				end++
+			}
+			if end == len(r) {
+				return nil, fmt.Errorf("unterminated class in condition %v", s)
+			}
+			c := condition{runes: r[i+1 : end]}
+			if len(c.runes) > 0 && c.runes[0] == '^' {
+				c.negate = true
+				c.runes = c.runes[1:]
+			}
+			conds = append(conds, c)
+			i = end
+		default:
+			conds = append(conds, condition{runes: r[i : i+1]})
+		}
+	}
+	// A lone "." means the rule always applies
+	if len(conds) == 1 && conds[0].any {
+		return nil, nil
+	}
+	return conds, nil
+}
+
+// Split a flag field into its flags according to the FLAG type
+func (a *affixes) splitFlags(s string) []string {
+	flags := []string{}
+	switch a.flagType {
+	case "long":
+		r := []rune(s)
+		for i := 0; i+1 < len(r); i += 2 {
+			flags = append(flags, string(r[i:i+2]))
+		}
+	case "num":
+		for _, f := range strings.Split(s, ",") {
+			if f != "" {
+				flags = append(flags, f)
+			}
+		}
+	default:
+		for _, r := range s {
+			flags = append(flags, string(r))
+		}
+	}
+	return flags
+}
+
+// Split a flag field, resolving AF aliases
+func (a *affixes) expandFlags(s string) []string {
+	if a.aliases != nil {
+		if i, err := strconv.Atoi(s); err == nil && i > 0 && i <= len(a.aliases) {
+			return a.aliases[i-1]
+		}
+	}
+	return a.splitFlags(s)
+}
+
+// Apply the rule to a word, returning false if it doesn't apply
+func (rule affixRule) apply(word []rune, prefix bool) ([]rune, bool) {
+	if len(rule.strip) >= len(word) || len(rule.condition) > len(word) {
+		return nil, false
+	}
+	if prefix {
+		for i, c := range rule.condition {
+			if !c.matches(word[i]) {
+				return nil, false
+			}
+		}
+		if string(word[:len(rule.strip)]) != string(rule.strip) {
+			return nil, false
+		}
+		w := append([]rune{}, rule.add...)
+		return append(w, word[len(rule.strip):]...), true
+	}
+
+	offset := len(word) - len(rule.condition)
+	for i, c := range rule.condition {
+		if !c.matches(word[offset+i]) {
+			return nil, false
+		}
+	}
+	stem := len(word) - len(rule.strip)
+	if string(word[stem:]) != string(rule.strip) {
+		return nil, false
+	}
+	w := append([]rune{}, word[:stem]...)
+	return append(w, rule.add...), true
+}
+
+func hasFlag(flags []string, flag string) bool {
+	if flag == "" {
+		return false
+	}
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// An affixed form of a stem
+type affixed struct {
+	word  []rune
+	cross bool
+	flags []string
+}
+
+// Apply the suffix classes named by flags to word, and then once more the
+// suffix classes named by each rule's continuation flags
+func (a *affixes) suffix(word []rune, flags []string, depth int) []affixed {
+	forms := []affixed{}
+	for _, flag := range flags {
+		class := a.suffixes[flag]
+		if class == nil {
+			continue
+		}
+		for _, rule := range class.rules {
+			w, ok := rule.apply(word, false)
+			if !ok {
+				continue
+			}
+			forms = append(forms, affixed{w, class.cross, rule.flags})
+			if depth == 0 {
+				forms = append(forms, a.suffix(w, rule.flags, 1)...)
+			}
+		}
+	}
+	return forms
+}
+
+// Every word formed from a stem and its flags. A forbidden stem forms none.
+func (a *affixes) expand(stem []rune, flags []string) [][]rune {
+	if hasFlag(flags, a.forbidden) || hasFlag(flags, a.compound) {
+		return nil
+	}
+	words := [][]rune{}
+	if !hasFlag(flags, a.needAffix) {
+		words = append(words, stem)
+	}
+
+	suffixed := a.suffix(stem, flags, 0)
+	for _, s := range suffixed {
+		if !hasFlag(s.flags, a.needAffix) {
+			words = append(words, s.word)
+		}
+	}
+
+	for _, flag := range flags {
+		class := a.prefixes[flag]
+		if class == nil {
+			continue
+		}
+		for _, rule := range class.rules {
+			if w, ok := rule.apply(stem, true); ok {
+				if !hasFlag(rule.flags, a.needAffix) {
+					words = append(words, w)
+				}
+				// Suffixes allowed by the prefix's continuation flags
+				for _, s := range a.suffix(w, rule.flags, 1) {
+					words = append(words, s.word)
+				}
+			}
+			if !class.cross {
+				continue
+			}
+			for _, s := range suffixed {
+				if !s.cross {
+					continue
+				}
+				if w, ok := rule.apply(s.word, true); ok {
+					words = append(words, w)
+				}
+			}
+		}
+	}
+	return words
+}
+
+// Insert every stem of a .dic file, and all its affixed forms, into the Trie.
+// A forbidden word is left out even if another stem's affixes form it.
+func (a *affixes) insertStems(t *Trie, r io.Reader, name string) error {
+	text, _, err := decodeHunspell(r, a.charset)
+	if err != nil {
+		return fmt.Errorf("reading %v: %w", name, err)
+	}
+
+	forbidden := []string{}
+	err = scanLines(strings.NewReader(text), name, func(line int, text string) error {
+		fields := strings.Fields(text)
+		// The first line is the number of stems
+		if line == 1 {
+			if _, err := strconv.Atoi(fields[0]); err == nil {
+				return nil
+			}
+		}
+		stem, flags := splitStem(fields[0])
+		if stem == "" {
+			return &ParseError{Name: name, Line: line, Text: text, Err: errors.New("empty word")}
+		}
+		expanded := a.expandFlags(flags)
+		if hasFlag(expanded, a.forbidden) {
+			forbidden = append(forbidden, stem)
+		}
+		for _, w := range a.expand([]rune(stem), expanded) {
+			t.InsertString(string(w))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, w := range forbidden {
+		t.RemoveString(w)
+	}
+	return nil
+}
+
+// Split a .dic entry into its word and flags at the first unescaped '/'
+func splitStem(entry string) (string, string) {
+	for i := 0; i < len(entry); i++ {
+		if entry[i] == '\\' && i+1 < len(entry) && entry[i+1] == '/' {
+			i++
+			continue
+		}
+		if entry[i] == '/' {
+			return strings.ReplaceAll(entry[:i], `\/`, "/"), entry[i+1:]
+		}
+	}
+	return strings.ReplaceAll(entry, `\/`, "/"), ""
+}
+
+// Read all of a Hunspell file and decode it to UTF-8. If charset is empty it
+// is taken from the SET line of the file, as for an .aff file. Returns the
+// text and the charset used.
+func decodeHunspell(r io.Reader, charset string) (string, string, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", "", err
+	}
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	if charset == "" {
+		// Hunspell's default
+		charset = "ISO8859-1"
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) > 1 && fields[0] == "SET" {
+				charset = strings.ToUpper(fields[1])
+				break
+			}
+		}
+	}
+
+	switch charset {
+	case "UTF-8":
+		return string(b), charset, nil
+	case "ISO8859-1", "ISO-8859-1":
+		return decodeLatin(b, nil), charset, nil
+	case "ISO8859-15", "ISO-8859-15":
+		return decodeLatin(b, latin9), charset, nil
+	}
+	return "", "", fmt.Errorf("unsupported character set %v", charset)
+}
+
+// The code points where ISO 8859-15 differs from ISO 8859-1
+var latin9 = map[byte]rune{
+	0xa4: '€', 0xa6: 'Š', 0xa8: 'š', 0xb4: 'Ž',
+	0xb8: 'ž', 0xbc: 'Œ', 0xbd: 'œ', 0xbe: 'Ÿ',
+}
+
+func decodeLatin(b []byte, overrides map[byte]rune) string {
+	r := make([]rune, len(b))
+	for i, c := range b {
+		if o, ok := overrides[c]; ok {
+			r[i] = o
+		} else {
+			r[i] = rune(c)
+		}
+	}
+	return string(r)
+}
@@ -0,0 +1,143 @@
+package gospell
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testAff = `# Based on the example in hunspell(5)
+SET UTF-8
+TRY esianrtolcdugmphbyfvkwz
+
+PFX A Y 1
+PFX A   0     re         .
+
+SFX D Y 4
+SFX D   0     d          e
+SFX D   y     ied        [^aey]y
+SFX D   0     ed         [^ey]
+SFX D   0     ed         [aey]y
+
+SFX S N 1
+SFX S   0     s          .
+
+NEEDAFFIX X
+FORBIDDENWORD F
+`
+
+const testDic = `5
+create/AD
+try/DS
+work/ADS
+wr\/ote
+works/F
+`
+
+func assertWords(t *testing.T, trie *Trie, expected []string) {
+	t.Helper()
+	if !HasEveryElement(t, trie.AllFullChildren(), expected) {
+		t.Errorf("Expected %v, got %v", expected, trie.AllFullChildren())
+	}
+}
+
+func TestTrieFromHunspell(t *testing.T) {
+	trie, err := TrieFromHunspellReader(strings.NewReader(testAff), strings.NewReader(testDic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, trie, []string{
+		"create", "created", "recreate", "recreated",
+		"try", "tried", "trys",
+		"work", "worked", "rework", "reworked",
+		"wr/ote",
+	})
+
+	// "reworks" needs the prefix and a suffix without cross products
+	if trie.ContainsString("reworks") {
+		t.Error("reworks shouldn't be formed")
+	}
+	// "works" is formed from "work/S" but forbidden
+	if trie.ContainsString("works") {
+		t.Error("works is forbidden")
+	}
+	suggestions := trie.SuggestWords("reworkd", 1)
+	if len(suggestions) != 2 || suggestions[0] != "rework" || suggestions[1] != "reworked" {
+		t.Errorf("Wrong suggestions %v", suggestions)
+	}
+
+	fsys := fstest.MapFS{
+		"en.aff": &fstest.MapFile{Data: []byte(testAff)},
+		"en.dic": &fstest.MapFile{Data: []byte(testDic)},
+	}
+	trie, err = TrieFromHunspellFS(fsys, "en.aff", "en.dic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trie.ContainsString("recreated") {
+		t.Error("recreated not found")
+	}
+}
+
+func TestHunspellFlags(t *testing.T) {
+	tests := []struct {
+		aff, dic string
+		expected []string
+	}{
+		// Long flags, continuation flags and NEEDAFFIX
+		{"FLAG long\nNEEDAFFIX Xx\nSFX Aa Y 1\nSFX Aa 0 ful/Bb .\nSFX Bb Y 1\nSFX Bb 0 ly .\n",
+			"1\nhope/AaXx\n",
+			[]string{"hopeful", "hopefully"}},
+		// Numeric flags
+		{"FLAG num\nPFX 101 N 1\nPFX 101 0 un .\nSFX 7 N 1\nSFX 7 e ing e\n",
+			"2\ndo/101\nmake/7,101\n",
+			[]string{"do", "undo", "make", "making", "unmake"}},
+		// Flag aliases
+		{"AF 2\nAF AB\nAF B\nPFX A Y 1\nPFX A 0 pre .\nSFX B Y 1\nSFX B 0 s .\n",
+			"2\nview/1\nfix/2\n",
+			[]string{"view", "views", "preview", "previews", "fix", "fixs"}},
+	}
+
+	for _, test := range tests {
+		trie, err := TrieFromHunspellReader(strings.NewReader(test.aff), strings.NewReader(test.dic))
+		if err != nil {
+			t.Errorf("%q: %v", test.aff, err)
+			continue
+		}
+		assertWords(t, trie, test.expected)
+	}
+}
+
+func TestHunspellLatin1(t *testing.T) {
+	aff := "SET ISO8859-1\nSFX A Y 1\nSFX A 0 \xe9 .\n"
+	dic := "1\ncaf/A\n"
+	trie, err := TrieFromHunspellReader(strings.NewReader(aff), strings.NewReader(dic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertWords(t, trie, []string{"caf", "café"})
+}
+
+func TestHunspellErrors(t *testing.T) {
+	tests := []struct {
+		aff  string
+		line int
+	}{
+		{"SET UTF-8\nSFX A Q 1\n", 2},
+		{"SFX A Y 1\nSFX A 0 s [abc\n", 2},
+		{"FLAG wide\n", 1},
+	}
+	for _, test := range tests {
+		_, err := TrieFromHunspellReader(strings.NewReader(test.aff), strings.NewReader("1\nword\n"))
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Line != test.line {
+			t.Errorf("%q: expected an error on line %d, got %v", test.aff, test.line, err)
+		}
+	}
+
+	_, err := TrieFromHunspellReader(strings.NewReader("SET KOI8-R\n"), strings.NewReader(""))
+	if err == nil {
+		t.Error("Unsupported character sets should be reported")
+	}
+}
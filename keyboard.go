@@ -0,0 +1,122 @@
+package gospell
+
+import (
+	"sync"
+	"unicode"
+)
+
+// A KeyboardLayout knows which keys neighbour each other, so that the typos
+// of someone typing on it, which mostly hit a key beside the intended one,
+// can cost less than other edits. See SuggestOptions.Keyboard.
+type KeyboardLayout struct {
+	name       string
+	neighbours map[[2]rune]bool
+}
+
+// The horizontal offset of each row of a staggered keyboard from the top
+// letter row, in keys
+var rowOffsets = []float64{0, 0.25, 0.75, 1.25}
+
+// Create a KeyboardLayout from the rows of keys typed without shift, starting
+// with the top letter row, e.g. "qwertyuiop", "asdfghjkl", "zxcvbnm" for
+// QWERTY. Each row is staggered from the one above as on a standard keyboard,
+// and keys touching each other are neighbours. Keys are matched ignoring case.
+func NewKeyboardLayout(name string, rows ...string) *KeyboardLayout {
+	type key struct {
+		r   rune
+		row int
+		x   float64
+	}
+	keys := []key{}
+	for i, row := range rows {
+		offset := rowOffsets[min(i, len(rowOffsets)-1)]
+		for j, r := range runes(row) {
+			keys = append(keys, key{unicode.ToLower(r), i, float64(j) + offset})
+		}
+	}
+
+	k := &KeyboardLayout{name: name, neighbours: map[[2]rune]bool{}}
+	for _, a := range keys {
+		for _, b := range keys {
+			dx := a.x - b.x
+			if dx < 0 {
+				dx = -dx
+			}
+			sameRow := a.row == b.row && dx == 1
+			nextRow := (a.row-b.row == 1 || b.row-a.row == 1) && dx < 1
+			if sameRow || nextRow {
+				k.neighbours[[2]rune{a.r, b.r}] = true
+			}
+		}
+	}
+	return k
+}
+
+// The name of the KeyboardLayout
+func (k *KeyboardLayout) Name() string {
+	return k.name
+}
+
+// Whether the keys for a and b are next to each other
+func (k *KeyboardLayout) Adjacent(a, b rune) bool {
+	return k.neighbours[[2]rune{unicode.ToLower(a), unicode.ToLower(b)}]
+}
+
+// Whether typing b by mistake next to a is likely: the same key twice or a
+// neighbouring one
+func (k *KeyboardLayout) slip(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b) || k.Adjacent(a, b)
+}
+
+// The built-in KeyboardLayouts, which are always registered
+var (
+	QWERTY = NewKeyboardLayout("qwerty", "qwertyuiop", "asdfghjkl", "zxcvbnm")
+	AZERTY = NewKeyboardLayout("azerty", "azertyuiop", "qsdfghjklm", "wxcvbn")
+	QWERTZ = NewKeyboardLayout("qwertz", "qwertzuiopü", "asdfghjklöä", "yxcvbnm")
+	Dvorak = NewKeyboardLayout("dvorak", "',.pyfgcrl", "aoeuidhtns", ";qjkxbmwvz")
+)
+
+var (
+	keyboardsMu sync.RWMutex
+	keyboards   = map[string]*KeyboardLayout{}
+)
+
+func init() {
+	for _, k := range []*KeyboardLayout{QWERTY, AZERTY, QWERTZ, Dvorak} {
+		RegisterKeyboardLayout(k)
+	}
+}
+
+// Register a KeyboardLayout by its name, replacing any registered with the
+// same name, so that it can be found with KeyboardLayoutByName
+func RegisterKeyboardLayout(k *KeyboardLayout) {
+	keyboardsMu.Lock()
+	defer keyboardsMu.Unlock()
+	keyboards[k.name] = k
+}
+
+// Get a registered KeyboardLayout, such as "qwerty", "azerty", "qwertz" or
+// "dvorak"
+func KeyboardLayoutByName(name string) (*KeyboardLayout, bool) {
+	keyboardsMu.RLock()
+	defer keyboardsMu.RUnlock()
+	k, ok := keyboards[name]
+	return k, ok
+}
+
+// Options for suggesting corrections of text typed on keyboard, within
+// `distance` edits. Every edit costs 2, except substituting a neighbouring
+// key, and adding or deleting a key beside or the same as the one before or
+// after it, which cost 1. So e.g. "hrllo" suggests "hello" before "hallo",
+// and "helo" suggests "hello" before "heldo".
+func TypingOptions(distance int, keyboard *KeyboardLayout) SuggestOptions {
+	return SuggestOptions{
+		Distance:          2 * distance,
+		AdditionCost:      2,
+		DeletionCost:      2,
+		SubstitutionCost:  2,
+		TranspositionCost: 2,
+		Keyboard:          keyboard,
+		KeyboardCost:      1,
+	}
+}
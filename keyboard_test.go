@@ -0,0 +1,92 @@
+package gospell
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestKeyboardLayouts(t *testing.T) {
+	neighbours := func(k *KeyboardLayout, r rune) string {
+		found := []rune{}
+		for key := range k.neighbours {
+			if key[0] == r {
+				found = append(found, key[1])
+			}
+		}
+		sort.Slice(found, func(i, j int) bool { return found[i] < found[j] })
+		return string(found)
+	}
+	tests := []struct {
+		name     string
+		key      rune
+		expected string
+	}{
+		{"qwerty", 's', "adewxz"},
+		{"qwerty", 'q', "aw"},
+		{"qwerty", 'm', "jkn"},
+		{"azerty", 'q', "aswz"},
+		{"qwertz", 'z', "ghtu"},
+		{"qwertz", 'ö', "lpäü"},
+		{"dvorak", 'e', ".jopqu"},
+	}
+	for _, test := range tests {
+		k, ok := KeyboardLayoutByName(test.name)
+		if !ok {
+			t.Fatalf("%v isn't registered", test.name)
+		}
+		if got := neighbours(k, test.key); got != test.expected {
+			t.Errorf("%v: %q should neighbour %q, got %q", test.name, test.key, test.expected, got)
+		}
+	}
+	if !QWERTY.Adjacent('S', 'e') || QWERTY.Adjacent('s', 's') || QWERTY.Adjacent('s', 'r') {
+		t.Error("Wrong adjacency")
+	}
+
+	custom := NewKeyboardLayout("abc", "abc", "def")
+	RegisterKeyboardLayout(custom)
+	if k, ok := KeyboardLayoutByName("abc"); !ok || k != custom || k.Name() != "abc" {
+		t.Error("Custom layouts should be registered")
+	}
+	if _, ok := KeyboardLayoutByName("colemak"); ok {
+		t.Error("Unknown layouts shouldn't be found")
+	}
+}
+
+func TestTypingOptions(t *testing.T) {
+	trie := NewTrie()
+	for _, w := range []string{"hello", "hallo", "hullo", "help", "heldo"} {
+		trie.InsertString(w)
+	}
+
+	tests := []struct {
+		s        string
+		keyboard *KeyboardLayout
+		expected []string
+	}{
+		{"hrllo", QWERTY, []string{"hello", "hallo", "hullo"}},
+		{"hrllo", nil, []string{"hallo", "hello", "hullo"}},
+		// A stray key beside the one before or after it
+		{"hellpo", QWERTY, []string{"hello", "help"}},
+		{"hellpo", nil, []string{"hello"}},
+		// A dropped key beside or the same as the one before or after it
+		{"helo", QWERTY, []string{"hello", "help", "heldo"}},
+		{"helo", nil, []string{"heldo", "hello", "help"}},
+		{"hdllo", QWERTY, []string{"hello", "hallo", "hullo"}},
+		{"hdllo", Dvorak, []string{"hallo", "hello", "hullo"}},
+		{"hzllo", QWERTZ, []string{"hullo", "hallo", "hello"}},
+	}
+	name := func(k *KeyboardLayout) string {
+		if k == nil {
+			return "no keyboard"
+		}
+		return k.Name()
+	}
+	for _, test := range tests {
+		opts := TypingOptions(1, test.keyboard)
+		suggestions := trie.Suggest(test.s, opts)
+		if got := suggestions.Strings(); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("Suggest(%q) on %v = %v, expected %v", test.s, name(test.keyboard), got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package gospell
+
+import (
+	"io"
+	"strings"
+)
+
+// The lookups and suggestions shared by every Lexicon other than Trie, which
+// each embeds with its root, so that they only define what is particular to
+// them. They all work through the node interface.
+type lexicon[N node] struct {
+	root N
+}
+
+func (l lexicon[N]) rootNode() node { return l.root }
+
+// Return true if the word in a strings.Reader is Contained. See
+// Trie.Contains.
+func (l lexicon[N]) Contains(s *strings.Reader) bool {
+	rest, _ := io.ReadAll(s)
+	return contains(l.root, string(rest))
+}
+
+// Check if a String is Contained
+func (l lexicon[N]) ContainsString(s string) bool {
+	return contains(l.root, s)
+}
+
+// Get the weight of a word, or 0 if it isn't Contained
+func (l lexicon[N]) Weight(s string) int {
+	n := get(l.root, s)
+	if n == nil {
+		return 0
+	}
+	if leaf, weight := n.terminal(); leaf {
+		return weight
+	}
+	return 0
+}
+
+// Get all of the complete child words, in rune order
+func (l lexicon[N]) AllFullChildren() []string {
+	return allWords(l.root)
+}
+
+// See Trie.Walk
+func (l lexicon[N]) Walk(c Collation, fn func(word string, weight int) bool) bool {
+	return walk(l.root, c, func(word []rune, weight int) bool {
+		return fn(string(word), weight)
+	})
+}
+
+// See Trie.Complete
+func (l lexicon[N]) Complete(prefix string, k int) []string {
+	return complete(l.root, runes(prefix), k)
+}
+
+// See Trie.FuzzyComplete
+func (l lexicon[N]) FuzzyComplete(prefix string, distance, k int) []string {
+	return completeFuzzy(l.root, runes(prefix), distance, k).Strings()
+}
+
+// See Trie.Deletions
+func (l lexicon[N]) Deletions(s string, distance int) []string {
+	return l.Suggest(s, SuggestOptions{Distance: distance, Edits: Deletions}).Strings()
+}
+
+// See Trie.Additions
+func (l lexicon[N]) Additions(s string, distance int) []string {
+	return l.Suggest(s, SuggestOptions{Distance: distance, Edits: Additions}).Strings()
+}
+
+// See Trie.Substitutions
+func (l lexicon[N]) Substitutions(s string, distance int) []string {
+	return l.Suggest(s, SuggestOptions{Distance: distance, Edits: Substitutions}).Strings()
+}
+
+// See Trie.Transpositions
+func (l lexicon[N]) Transpositions(s string, distance int) []string {
+	return l.Suggest(s, SuggestOptions{Distance: distance, Edits: Transpositions}).Strings()
+}
+
+// See Trie.Anagrams
+func (l lexicon[N]) Anagrams(s string) []string {
+	r := runes(s)
+	return anagrams(l.root, r, r).Strings()
+}
+
+// See Trie.Suggest
+func (l lexicon[N]) Suggest(s string, opts SuggestOptions) Matches {
+	return suggestWith(l.root, runes(s), opts)
+}
+
+// See Trie.SuggestWords
+func (l lexicon[N]) SuggestWords(s string, distance int) []string {
+	return l.Suggest(s, SuggestOptions{Distance: distance}).Strings()
+}
+
+// See Trie.SuggestTopK
+func (l lexicon[N]) SuggestTopK(s string, distance, k int) []string {
+	return suggestTopK(l.root, runes(s), distance, k).Strings()
+}
@@ -0,0 +1,296 @@
+package gospell
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// The flat format written by Trie.WriteMapped is laid out so it can be used
+// in place, without decoding. All integers are little-endian:
+//
+//	header  magic, version, node count and edge count
+//	nodes   one per node: first edge, edge count (the top bit marks a leaf),
+//	        weight and the weight of the heaviest word under the node
+//	edges   one per child, grouped by parent and sorted by rune: the rune
+//	        and the index of the child node
+//	trailer a CRC-32 of everything before it
+//
+// Node 0 is the root, and every edge points to a node with a higher index, so
+// the nodes always form an acyclic graph. Nodes may be shared by several
+// parents.
+const (
+	mappedMagic   = "gospellm"
+	mappedVersion = 2
+
+	mappedHeaderSize = len(mappedMagic) + 12
+	mappedNodeSize   = 24
+	mappedEdgeSize   = 8
+	mappedLeafBit    = 1 << 31
+)
+
+// A MappedTrie is a read-only dictionary stored in the flat format written by
+// Trie.WriteMapped. It is used directly from the file's bytes without building
+// any Trie nodes, and when opened with OpenMapped the file is memory-mapped,
+// so processes using the same file share its pages.
+type MappedTrie struct {
+	lexicon[mappedNode]
+	nodes []byte
+	edges []byte
+	close func() error
+}
+
+// Open a dictionary written by Trie.WriteMapped. The file is memory-mapped
+// where the platform supports it and read into memory otherwise. Call Close
+// when the MappedTrie is no longer needed.
+func OpenMapped(fname string) (*MappedTrie, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
+	}
+	defer f.Close()
+
+	data, unmap, err := mmapFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("mapping %v: %w", fname, err)
+	}
+	m, err := NewMappedTrie(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	m.close = unmap
+	return m, nil
+}
+
+// Use data written by Trie.WriteMapped, such as an embedded file, as a
+// MappedTrie without copying it. The data must not be modified while the
+// MappedTrie is in use. Bad data is reported with ErrNotDictionary, ErrVersion
+// or ErrCorrupt.
+func NewMappedTrie(data []byte) (*MappedTrie, error) {
+	if !bytes.HasPrefix(data, []byte(mappedMagic)) {
+		return nil, ErrNotDictionary
+	}
+	if len(data) < mappedHeaderSize+4 {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrCorrupt)
+	}
+	le := binary.LittleEndian
+	header := data[len(mappedMagic):]
+	if v := le.Uint32(header); v != mappedVersion {
+		return nil, fmt.Errorf("%w %d, expected %d", ErrVersion, v, mappedVersion)
+	}
+	nodeCount := uint64(le.Uint32(header[4:]))
+	edgeCount := uint64(le.Uint32(header[8:]))
+	size := uint64(mappedHeaderSize) + nodeCount*mappedNodeSize + edgeCount*mappedEdgeSize
+	if nodeCount == 0 || size+4 != uint64(len(data)) {
+		return nil, fmt.Errorf("%w: wrong size", ErrCorrupt)
+	}
+	if crc32.ChecksumIEEE(data[:size]) != le.Uint32(data[size:]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+
+	nodesEnd := mappedHeaderSize + int(nodeCount)*mappedNodeSize
+	m := &MappedTrie{
+		nodes: data[mappedHeaderSize:nodesEnd],
+		edges: data[nodesEnd:size],
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	m.root = mappedNode{m, 0}
+	return m, nil
+}
+
+// Check every node and edge, so that walking the MappedTrie can't go out of
+// bounds or loop forever
+func (m *MappedTrie) validate() error {
+	nodeCount := uint32(len(m.nodes) / mappedNodeSize)
+	edgeCount := uint32(len(m.edges) / mappedEdgeSize)
+	for i := uint32(0); i < nodeCount; i++ {
+		first, count, _, _ := m.node(i)
+		if uint64(first)+uint64(count) > uint64(edgeCount) {
+			return fmt.Errorf("%w: node %d has bad edges", ErrCorrupt, i)
+		}
+		last := int64(-1)
+		for e := first; e < first+count; e++ {
+			r, target := m.edge(e)
+			if r > utf8.MaxRune || int64(r) <= last {
+				return fmt.Errorf("%w: bad rune %#x", ErrCorrupt, r)
+			}
+			if target <= i || target >= nodeCount {
+				return fmt.Errorf("%w: node %d has a bad child %d", ErrCorrupt, i, target)
+			}
+			last = int64(r)
+		}
+	}
+	return nil
+}
+
+// Release the memory-mapped file. The MappedTrie, and any MappedTrie returned
+// by its Get method, must not be used afterwards.
+func (m *MappedTrie) Close() error {
+	if m.close == nil {
+		return nil
+	}
+	err := m.close()
+	m.close = nil
+	return err
+}
+
+// Read node i
+func (m *MappedTrie) node(i uint32) (first, count uint32, leaf bool, weight int) {
+	b := m.nodes[int(i)*mappedNodeSize:]
+	first = binary.LittleEndian.Uint32(b)
+	count = binary.LittleEndian.Uint32(b[4:])
+	leaf = count&mappedLeafBit != 0
+	weight = int(int64(binary.LittleEndian.Uint64(b[8:])))
+	return first, count &^ mappedLeafBit, leaf, weight
+}
+
+// Read the weight of the heaviest word under node i
+func (m *MappedTrie) heaviest(i uint32) int {
+	b := m.nodes[int(i)*mappedNodeSize+16:]
+	return int(int64(binary.LittleEndian.Uint64(b)))
+}
+
+// Read edge e
+func (m *MappedTrie) edge(e uint32) (rune, uint32) {
+	b := m.edges[int(e)*mappedEdgeSize:]
+	return rune(binary.LittleEndian.Uint32(b)), binary.LittleEndian.Uint32(b[4:])
+}
+
+// A node of a MappedTrie
+type mappedNode struct {
+	m *MappedTrie
+	i uint32
+}
+
+func (n mappedNode) terminal() (bool, int) {
+	_, _, leaf, weight := n.m.node(n.i)
+	return leaf, weight
+}
+
+func (n mappedNode) heaviestWeight() int { return n.m.heaviest(n.i) }
+
+func (n mappedNode) child(r rune) node {
+	first, count, _, _ := n.m.node(n.i)
+	e := first + uint32(sort.Search(int(count), func(j int) bool {
+		c, _ := n.m.edge(first + uint32(j))
+		return c >= r
+	}))
+	if e < first+count {
+		if c, target := n.m.edge(e); c == r {
+			return mappedNode{n.m, target}
+		}
+	}
+	return nil
+}
+
+func (n mappedNode) each(fn func(rune, node) bool) bool {
+	first, count, _, _ := n.m.node(n.i)
+	for e := first; e < first+count; e++ {
+		r, target := n.m.edge(e)
+		if !fn(r, mappedNode{n.m, target}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the MappedTrie at the end of a strings.Reader. It shares the memory of
+// m.
+func (m *MappedTrie) Get(s *strings.Reader) *MappedTrie {
+	rest, _ := io.ReadAll(s)
+	n := get(m.rootNode(), string(rest))
+	if n == nil {
+		return nil
+	}
+	return &MappedTrie{lexicon: lexicon[mappedNode]{n.(mappedNode)}, nodes: m.nodes, edges: m.edges}
+}
+
+// Write the Trie to w in the flat format used by MappedTrie, returning the
+// number of bytes written
+func (t *Trie) WriteMapped(w io.Writer) (int64, error) {
+	return writeMapped(w, t)
+}
+
+// Write the nodes under root in the flat format. A node reached along several
+// paths is written once, and nodes are numbered so that every node comes after
+// all of its parents.
+func writeMapped(w io.Writer, root node) (int64, error) {
+	// Reverse postorder puts every node before its children. Postorder
+	// weighs every node's children before it.
+	order := []node{}
+	heaviest := map[node]int{}
+	var visit func(node)
+	visit = func(n node) {
+		h := -infinity
+		if leaf, weight := n.terminal(); leaf {
+			h = weight
+		}
+		heaviest[n] = h
+		for _, c := range sortedChildren(n, nil) {
+			if _, seen := heaviest[c.child]; !seen {
+				visit(c.child)
+			}
+			h = max(h, heaviest[c.child])
+		}
+		heaviest[n] = h
+		order = append(order, n)
+	}
+	visit(root)
+	index := make(map[node]uint32, len(order))
+	for i := range order {
+		index[order[len(order)-1-i]] = uint32(i)
+	}
+
+	nodes := []byte{}
+	edges := []byte{}
+	le := binary.LittleEndian
+	for i := len(order) - 1; i >= 0; i-- {
+		n := order[i]
+		children := sortedChildren(n, nil)
+		first := uint32(len(edges) / mappedEdgeSize)
+		count := uint32(len(children))
+		leaf, weight := n.terminal()
+		if leaf {
+			count |= mappedLeafBit
+		}
+		nodes = le.AppendUint32(nodes, first)
+		nodes = le.AppendUint32(nodes, count)
+		nodes = le.AppendUint64(nodes, uint64(int64(weight)))
+		nodes = le.AppendUint64(nodes, uint64(int64(heaviest[n])))
+		for _, c := range children {
+			edges = le.AppendUint32(edges, uint32(c.r))
+			edges = le.AppendUint32(edges, index[c.child])
+		}
+	}
+
+	header := []byte(mappedMagic)
+	header = le.AppendUint32(header, mappedVersion)
+	header = le.AppendUint32(header, uint32(len(order)))
+	header = le.AppendUint32(header, uint32(len(edges)/mappedEdgeSize))
+
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(w, crc))
+	var n int64
+	for _, b := range [][]byte{header, nodes, edges} {
+		written, err := bw.Write(b)
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return n, err
+	}
+	written, err := w.Write(le.AppendUint32(nil, crc.Sum32()))
+	return n + int64(written), err
+}
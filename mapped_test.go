@@ -0,0 +1,169 @@
+package gospell
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testTrie() *Trie {
+	trie := NewTrie()
+	for _, s := range []string{"toad", "todd", "load", "toads", "tod", "toda",
+		"today", "bad", "robert", "teddy", "ab狐d犬", "ba狐d犬"} {
+		trie.InsertString(s)
+	}
+	trie.InsertStringWeighted("the", 500)
+	trie.InsertStringWeighted("tea", 10)
+	return trie
+}
+
+func TestMappedTrie(t *testing.T) {
+	trie := testTrie()
+	fname := filepath.Join(t.TempDir(), "dict")
+	f, err := os.Create(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := trie.WriteMapped(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, _ := os.Stat(fname); fi.Size() != n {
+		t.Errorf("WriteMapped wrote %d bytes, reported %d", fi.Size(), n)
+	}
+
+	m, err := OpenMapped(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if !HasEveryElement(t, m.AllFullChildren(), trie.AllFullChildren()) {
+		t.Errorf("Expected %v, got %v", trie.AllFullChildren(), m.AllFullChildren())
+	}
+	for _, s := range []string{"toad", "ab狐d犬", "to", "toadstool", ""} {
+		if m.ContainsString(s) != trie.ContainsString(s) ||
+			m.Contains(strings.NewReader(s)) != trie.ContainsString(s) {
+			t.Errorf("Contains(%q) should be %t", s, trie.ContainsString(s))
+		}
+		if m.Weight(s) != trie.Weight(s) {
+			t.Errorf("Weight(%q) should be %d", s, trie.Weight(s))
+		}
+	}
+	if m.Weight("the") != 500 {
+		t.Errorf("Weight of 'the' is %d", m.Weight("the"))
+	}
+
+	sub := m.Get(strings.NewReader("toa"))
+	if sub == nil || !HasEveryElement(t, sub.AllFullChildren(), []string{"d", "ds"}) {
+		t.Error("Get should return the words under a prefix")
+	}
+	if m.Get(strings.NewReader("xyz")) != nil {
+		t.Error("Get of a missing prefix should be nil")
+	}
+
+	type op func(string, int) []string
+	ops := map[string][2]op{
+		"Deletions":      {trie.Deletions, m.Deletions},
+		"Additions":      {trie.Additions, m.Additions},
+		"Substitutions":  {trie.Substitutions, m.Substitutions},
+		"Transpositions": {trie.Transpositions, m.Transpositions},
+		"SuggestWords":   {trie.SuggestWords, m.SuggestWords},
+	}
+	for name, fns := range ops {
+		for _, s := range []string{"toad", "teh", "ab狐d犬", "x"} {
+			want := fns[0](s, 2)
+			got := fns[1](s, 2)
+			if strings.Join(want, ",") != strings.Join(got, ",") {
+				t.Errorf("%v(%q): expected %v, got %v", name, s, want, got)
+			}
+		}
+	}
+	if a := m.Anagrams("daot"); len(a) != 2 || a[0] != "toad" {
+		t.Errorf("Wrong anagrams %v", a)
+	}
+}
+
+func TestMappedTrieErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := testTrie().WriteMapped(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if _, err := NewMappedTrie(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewMappedTrie([]byte("hello, world")); !errors.Is(err, ErrNotDictionary) {
+		t.Errorf("Expected ErrNotDictionary, got %v", err)
+	}
+	newer := append([]byte{}, data...)
+	newer[len(mappedMagic)] = mappedVersion + 1
+	if _, err := NewMappedTrie(newer); !errors.Is(err, ErrVersion) {
+		t.Errorf("Expected ErrVersion, got %v", err)
+	}
+	for i := range data {
+		if _, err := NewMappedTrie(data[:i]); err == nil {
+			t.Errorf("Truncating to %d bytes wasn't detected", i)
+		}
+		damaged := append([]byte{}, data...)
+		damaged[i] ^= 0x5a
+		if _, err := NewMappedTrie(damaged); err == nil {
+			t.Errorf("Damaging byte %d wasn't detected", i)
+		}
+	}
+
+	// A consistent checksum doesn't excuse a cycle
+	trie := NewTrie()
+	trie.InsertString("ab")
+	buf.Reset()
+	trie.WriteMapped(&buf)
+	m, _ := NewMappedTrie(buf.Bytes())
+	cyclic := append([]byte{}, buf.Bytes()...)
+	// Point the edge from "a" back at the root and recompute the checksum
+	edge := mappedHeaderSize + len(m.nodes) + mappedEdgeSize + 4
+	cyclic[edge] = 0
+	size := len(cyclic) - 4
+	copy(cyclic[size:], crcBytes(cyclic[:size]))
+	if _, err := NewMappedTrie(cyclic); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected a cycle to be ErrCorrupt, got %v", err)
+	}
+}
+
+func crcBytes(b []byte) []byte {
+	return binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(b))
+}
+
+func BenchmarkMappedSuggestions2(b *testing.B) {
+	b.StopTimer()
+	trie, err := TrieFromFile("/usr/share/dict/words")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := trie.WriteMapped(&buf); err != nil {
+		b.Fatal(err)
+	}
+	m, err := NewMappedTrie(buf.Bytes())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	children := trie.AllFullChildren()
+	for i := 0; i < b.N; i++ {
+		for j, child := range children {
+			if j%1000 != 0 {
+				continue
+			}
+			b.StartTimer()
+			m.SuggestWords(child, 2)
+			b.StopTimer()
+		}
+	}
+}
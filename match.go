@@ -6,16 +6,23 @@ import (
 )
 
 type Match struct {
-	Word     []rune
+	Word []rune
+	// The total cost of the edits from the input, which is the number of
+	// edits unless SuggestOptions set other costs
 	Distance int
 	Weight   int
+	// The name of the Dictionary layer the word came from, if any
+	Layer string
+	// Whether the word sounds like the input. See SuggestOptions.Phonetic.
+	Phonetic bool
 }
 
 type Matches []Match
 
 func (m1 Match) Equal(m2 Match) bool {
 	return string(m1.Word) == string(m2.Word) &&
-		m1.Distance == m2.Distance && m1.Weight == m2.Weight
+		m1.Distance == m2.Distance && m1.Weight == m2.Weight &&
+		m1.Layer == m2.Layer && m1.Phonetic == m2.Phonetic
 }
 
 func (m Match) String() string {
@@ -25,10 +32,10 @@ func (m Match) String() string {
 func (s Matches) Len() int      { return len(s) }
 func (s Matches) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
-// Convert Matches to a list of strings
+// Convert Matches to a list of strings, ranked ByWeight
 func (s Matches) Strings() []string {
 	strings := make([]string, len(s))
-	sort.Sort(ByDistance{s})
+	sort.Sort(ByWeight{s})
 	for i, r := range s {
 		strings[i] = string(r.Word)
 	}
@@ -49,6 +56,24 @@ func (s ByDistance) Less(i, j int) bool {
 	return d1 < d2
 }
 
+// Rank Matches by Distance, then by Weight with the heaviest first, then
+// lexicographically. Without weights this is the same as ByDistance.
+type ByWeight struct {
+	Matches
+}
+
+func (s ByWeight) Less(i, j int) bool {
+	m1 := s.Matches[i]
+	m2 := s.Matches[j]
+	if m1.Distance != m2.Distance {
+		return m1.Distance < m2.Distance
+	}
+	if m1.Weight != m2.Weight {
+		return m1.Weight > m2.Weight
+	}
+	return string(m1.Word) < string(m2.Word)
+}
+
 func (m *Match) update(r rune, distance, weight int) Match {
 	m2 := Match{}
 	if r != 0 {
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package gospell
+
+import (
+	"io"
+	"os"
+)
+
+// Read the whole of f into memory, where memory-mapping isn't supported
+func mmapFile(f *os.File) ([]byte, func() error, error) {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,180 @@
+package gospell
+
+import (
+	"sort"
+	"unicode"
+)
+
+// A node is a position in one of the dictionary representations, such as a
+// Trie or a MappedTrie. The correction algorithms walk nodes, so they work the
+// same on every representation.
+type node interface {
+	// Whether the path to this node spells a word, and the word's weight
+	terminal() (leaf bool, weight int)
+	// The child reached by r, or nil
+	child(r rune) node
+	// Call fn for each child, in no particular order, until it returns false.
+	// Returns false if fn did.
+	each(fn func(r rune, child node) bool) bool
+}
+
+// A Lexicon is one of gospell's views of a word list: a Trie,
+// PersistentTrie, Radix, DAWG, MappedTrie or ConcurrentTrie. Lexicons can be
+// combined into a Dictionary.
+type Lexicon interface {
+	rootNode() node
+}
+
+func (t *Trie) rootNode() node { return t }
+
+func (t *Trie) terminal() (bool, int) { return t.leaf, t.weight }
+
+func (t *Trie) child(r rune) node {
+	if child := t.children[r]; child != nil {
+		return child
+	}
+	return nil
+}
+
+func (t *Trie) each(fn func(rune, node) bool) bool {
+	for r, child := range t.children {
+		if child != nil && !fn(r, child) {
+			return false
+		}
+	}
+	return true
+}
+
+// Follow s from n, returning nil if it isn't a path
+func get(n node, s string) node {
+	for _, r := range s {
+		if n = n.child(r); n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// Return true if the path s from n spells a word
+func contains(n node, s string) bool {
+	if s == "" {
+		return false
+	}
+	if n = get(n, s); n == nil {
+		return false
+	}
+	leaf, _ := n.terminal()
+	return leaf
+}
+
+// A Collation orders words for Walk, reporting whether the rune a sorts
+// before b. Words are compared rune by rune, where runes that neither sorts
+// before the other, such as 'A' and 'a' under CaseInsensitive, are equal.
+// Words equal at every rune are then ordered by rune value. A nil Collation
+// sorts by rune value.
+type Collation func(a, b rune) bool
+
+// Order words ignoring case, and words differing only in case by rune value,
+// which puts upper case first in ASCII
+func CaseInsensitive(a, b rune) bool {
+	return unicode.ToLower(a) < unicode.ToLower(b)
+}
+
+type edge struct {
+	r     rune
+	child node
+}
+
+// Get the children of n in collation order
+func sortedChildren(n node, c Collation) []edge {
+	children := []edge{}
+	n.each(func(r rune, child node) bool {
+		children = append(children, edge{r, child})
+		return true
+	})
+	if c == nil {
+		sort.Slice(children, func(i, j int) bool { return children[i].r < children[j].r })
+	} else {
+		sort.Slice(children, func(i, j int) bool { return c(children[i].r, children[j].r) })
+	}
+	return children
+}
+
+// A node reached by a walk, and the rune and node it was reached from
+type walked struct {
+	n      node
+	r      rune
+	parent *walked
+}
+
+// Spell the word of the first depth runes on the path to p
+func (p *walked) spell(depth int) []rune {
+	word := make([]rune, depth)
+	for i := depth - 1; i >= 0; i, p = i-1, p.parent {
+		word[i] = p.r
+	}
+	return word
+}
+
+// Call fn with every complete word under n, without the path to n, in
+// collation order, until it returns false. A word comes before the words it
+// is a prefix of. Returns false if fn did.
+//
+// The nodes reached by runes equal under the collation are visited together,
+// so that every word of the group is ordered against the others as a whole.
+func walk(n node, c Collation, fn func(word []rune, weight int) bool) bool {
+	if c == nil {
+		c = func(a, b rune) bool { return a < b }
+	}
+	type found struct {
+		word   []rune
+		weight int
+	}
+	var visit func(group []*walked, depth int) bool
+	visit = func(group []*walked, depth int) bool {
+		children := []*walked{}
+		for _, p := range group {
+			p.n.each(func(r rune, child node) bool {
+				children = append(children, &walked{child, r, p})
+				return true
+			})
+		}
+		sort.Slice(children, func(i, j int) bool { return c(children[i].r, children[j].r) })
+		for len(children) > 0 {
+			equal := 1
+			for equal < len(children) && !c(children[0].r, children[equal].r) {
+				equal++
+			}
+			group, children = children[:equal], children[equal:]
+			words := []found{}
+			for _, p := range group {
+				if leaf, weight := p.n.terminal(); leaf {
+					words = append(words, found{p.spell(depth + 1), weight})
+				}
+			}
+			sort.Slice(words, func(i, j int) bool {
+				return string(words[i].word) < string(words[j].word)
+			})
+			for _, w := range words {
+				if !fn(w.word, w.weight) {
+					return false
+				}
+			}
+			if !visit(group, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	return visit([]*walked{{n: n}}, 0)
+}
+
+// Get all of the complete words under n, without the path to n, in rune order
+func allWords(n node) []string {
+	words := []string{}
+	walk(n, nil, func(word []rune, _ int) bool {
+		words = append(words, string(word))
+		return true
+	})
+	return words
+}
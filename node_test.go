@@ -0,0 +1,90 @@
+package gospell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	trie := NewTrie()
+	for _, s := range []string{"b", "Ab", "a", "abc", "B", "ab", "狐"} {
+		trie.InsertString(s)
+	}
+	trie.InsertStringWeighted("abc", 3)
+
+	tests := []struct {
+		c        Collation
+		expected []string
+	}{
+		{nil, []string{"Ab", "B", "a", "ab", "abc", "b", "狐"}},
+		{CaseInsensitive, []string{"a", "Ab", "ab", "abc", "B", "b", "狐"}},
+		{func(a, b rune) bool { return a > b }, []string{"狐", "b", "a", "ab", "abc", "B", "Ab"}},
+	}
+	layouts := map[string]interface {
+		Walk(Collation, func(string, int) bool) bool
+	}{
+		"Trie":           trie,
+		"PersistentTrie": trie.Snapshot(),
+		"Radix":          trie.Compact(),
+		"MappedTrie":     mappedTrie(t, trie),
+		"DAWG":           dawgOf(t, trie),
+		"ConcurrentTrie": NewConcurrentTrie(trie),
+	}
+	for name, layout := range layouts {
+		for _, test := range tests {
+			words := []string{}
+			layout.Walk(test.c, func(word string, weight int) bool {
+				if word == "abc" && weight != 3 {
+					t.Errorf("%v: wrong weight %d for %q", name, weight, word)
+				}
+				words = append(words, word)
+				return true
+			})
+			if !reflect.DeepEqual(words, test.expected) {
+				t.Errorf("%v: walked %v, expected %v", name, words, test.expected)
+			}
+		}
+	}
+
+	// Words are ordered ignoring case as a whole, not just at their first
+	// rune differing in case
+	cased := NewTrie()
+	for _, s := range []string{"Ab", "aa", "ac", "aB", "AA"} {
+		cased.InsertString(s)
+	}
+	words := []string{}
+	cased.Walk(CaseInsensitive, func(word string, _ int) bool {
+		words = append(words, word)
+		return true
+	})
+	if expected := []string{"AA", "aa", "Ab", "aB", "ac"}; !reflect.DeepEqual(words, expected) {
+		t.Errorf("Walked %v ignoring case, expected %v", words, expected)
+	}
+
+	// Walking stops early
+	words = []string{}
+	finished := trie.Walk(nil, func(word string, _ int) bool {
+		words = append(words, word)
+		return len(words) < 3
+	})
+	if finished || !reflect.DeepEqual(words, []string{"Ab", "B", "a"}) {
+		t.Errorf("Walk didn't stop after 3 words: %v", words)
+	}
+}
+
+func TestDeterministicOrder(t *testing.T) {
+	trie := testTrie()
+	words := trie.AllFullChildren()
+	str := trie.String()
+	for i := 0; i < 10; i++ {
+		if !reflect.DeepEqual(trie.AllFullChildren(), words) {
+			t.Fatal("AllFullChildren should always return the same order")
+		}
+		if trie.String() != str {
+			t.Fatal("String should always return the same string")
+		}
+	}
+	if !reflect.DeepEqual(words[:3], []string{"ab狐d犬", "bad", "ba狐d犬"}) {
+		t.Errorf("Words aren't in rune order: %v", words)
+	}
+}
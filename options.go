@@ -0,0 +1,184 @@
+package gospell
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// SuggestOptions configures a spelling suggestion query. The zero value,
+// apart from Distance, behaves like SuggestWords.
+type SuggestOptions struct {
+	// The greatest total cost of the edits between the input and a suggestion
+	Distance int
+	// The kinds of edit allowed, such as Deletions|Substitutions. Zero allows
+	// AllEdits.
+	Edits Edits
+	// The cost of each kind of edit. Zero costs 1. Match.Distance is the
+	// total cost of a suggestion's edits, so with costs other than 1 it is no
+	// longer a count of edits.
+	AdditionCost, DeletionCost, SubstitutionCost, TranspositionCost int
+	// The costs of substituting particular pairs of runes, overriding
+	// SubstitutionCost. With IgnoreCase they are looked up in lower case.
+	SubstitutionCosts SubstitutionMatrix
+	// The keyboard the input was typed on, if known. Substituting a key for
+	// a neighbouring one, adding back a key dropped beside or on the same key
+	// as the one before or after it, or deleting an extra key typed there,
+	// costs KeyboardCost when that is less than the edit's own cost. Zero
+	// costs 1. See TypingOptions.
+	Keyboard     *KeyboardLayout
+	KeyboardCost int
+	// The most suggestions to return, or zero for all of them. A limit lets
+	// the search skip branches that can't beat the suggestions already found.
+	Limit int
+	// Compare runes ignoring case, so that "paris" suggests "Paris" at a
+	// distance of 0
+	IgnoreCase bool
+	// Order suggestions, such as with ByDistance. Nil ranks ByWeight.
+	Rank func(Matches) sort.Interface
+	// Also suggest the words that sound like the input, however far away,
+	// such as "physics" for "fizzix". Their Distance is the cost of their
+	// edits, and every suggestion that sounds like the input is marked
+	// Phonetic.
+	Phonetic *PhoneticIndex
+}
+
+// Return spelling suggestions for s configured by opts, ranked by opts.Rank.
+// Deletions, Additions, Substitutions, Transpositions, SuggestWords and
+// SuggestTopK are presets of Suggest, e.g. Deletions(s, 2) is
+// Suggest(s, SuggestOptions{Distance: 2, Edits: Deletions}).Strings().
+func (t *Trie) Suggest(s string, opts SuggestOptions) Matches {
+	return suggestWith(t, runes(s), opts)
+}
+
+// Make a searcher for r configured by opts
+func (o *SuggestOptions) searcher(r []rune, yield func(Match) bool) *searcher {
+	allowed := o.Edits
+	if allowed == 0 {
+		allowed = AllEdits
+	}
+	s := newSearcher(r, o.Distance, allowed, yield)
+	for _, c := range []struct{ cost, option *int }{
+		{&s.addCost, &o.AdditionCost},
+		{&s.deleteCost, &o.DeletionCost},
+		{&s.substituteCost, &o.SubstitutionCost},
+		{&s.transposeCost, &o.TranspositionCost},
+		{&s.keyCost, &o.KeyboardCost},
+	} {
+		if *c.option > 0 {
+			*c.cost = *c.option
+		}
+	}
+	s.matrix = o.SubstitutionCosts
+	s.keyboard = o.Keyboard
+	if o.IgnoreCase {
+		s.ignoreCase()
+	}
+	return s
+}
+
+// A SubstitutionMatrix holds the costs of substituting one rune for another,
+// so that confusable pairs, such as a and e or m and n, can be cheaper than
+// unrelated ones. Costs are whole numbers, so to make some substitutions
+// cheaper than 1, scale every cost up: e.g. a SubstitutionCost, AdditionCost,
+// DeletionCost and TranspositionCost of 2, and a cost of 1 for confusable
+// pairs. Negative costs count as 0, as the search relies on no edit making a
+// word closer.
+type SubstitutionMatrix map[[2]rune]int
+
+// Create a SubstitutionMatrix
+func NewSubstitutionMatrix() SubstitutionMatrix {
+	return make(SubstitutionMatrix)
+}
+
+// Set the cost of replacing a in the input with b in a suggestion, and b with
+// a
+func (m SubstitutionMatrix) Set(a, b rune, cost int) {
+	m.SetOneWay(a, b, cost)
+	m.SetOneWay(b, a, cost)
+}
+
+// Set the cost of replacing from in the input with to in a suggestion, but
+// not the reverse, such as SetOneWay('1', 'l', 1) for an OCR engine which
+// misreads "l" as "1" but never "1" as "l"
+func (m SubstitutionMatrix) SetOneWay(from, to rune, cost int) {
+	m[[2]rune{from, to}] = max(cost, 0)
+}
+
+// Get the cost of replacing a in the input with b in a suggestion, if it has
+// been Set
+func (m SubstitutionMatrix) Cost(a, b rune) (int, bool) {
+	cost, ok := m[[2]rune{a, b}]
+	return cost, ok
+}
+
+func (o *SuggestOptions) rank(m Matches) sort.Interface {
+	if o.Rank == nil {
+		return ByWeight{m}
+	}
+	return o.Rank(m)
+}
+
+// Find the suggestions for r under root configured by opts, adding the
+// words that sound like it from opts.Phonetic
+func suggestWith(root node, r []rune, opts SuggestOptions) Matches {
+	suggestions := suggestEdits(root, r, opts)
+	if opts.Phonetic == nil {
+		return suggestions
+	}
+	suggestions = opts.Phonetic.addSoundsLike(root, r, opts, suggestions)
+	sort.Sort(opts.rank(suggestions))
+	if opts.Limit > 0 && len(suggestions) > opts.Limit {
+		suggestions = suggestions[:opts.Limit]
+	}
+	return suggestions
+}
+
+// Find the suggestions for r within opts.Distance edits. With a Limit,
+// the worst of the best suggestions so far is kept at the top of a heap. If
+// they are ranked ByWeight, which puts Distance first, once there are enough
+// the search distance shrinks to the worst one's, pruning every branch that
+// can't beat it.
+func suggestEdits(root node, r []rune, opts SuggestOptions) Matches {
+	if opts.Limit <= 0 {
+		suggestions := Matches{}
+		opts.searcher(r, func(m Match) bool {
+			suggestions = append(suggestions, m)
+			return true
+		}).walk(root)
+		sort.Sort(opts.rank(suggestions))
+		return suggestions
+	}
+
+	best := &worstFirst{rank: opts.rank}
+	s := opts.searcher(r, nil)
+	s.greedy = true
+	s.yield = func(m Match) bool {
+		heap.Push(best, m)
+		if best.Len() > opts.Limit {
+			heap.Pop(best)
+		}
+		if best.Len() == opts.Limit && opts.Rank == nil {
+			s.distance = best.Matches[0].Distance
+		}
+		return true
+	}
+	s.walk(root)
+	sort.Sort(opts.rank(best.Matches))
+	return best.Matches
+}
+
+// A heap of Matches with the worst ranked on top
+type worstFirst struct {
+	Matches
+	rank func(Matches) sort.Interface
+}
+
+func (h worstFirst) Less(i, j int) bool { return h.rank(h.Matches).Less(j, i) }
+
+func (h *worstFirst) Push(x any) { h.Matches = append(h.Matches, x.(Match)) }
+
+func (h *worstFirst) Pop() any {
+	m := h.Matches[len(h.Matches)-1]
+	h.Matches = h.Matches[:len(h.Matches)-1]
+	return m
+}
@@ -0,0 +1,216 @@
+package gospell
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSuggestOptions(t *testing.T) {
+	trie := testTrie()
+	trie.InsertStringWeighted("Paris", 3)
+	words := func(m Matches) []string {
+		words := make([]string, len(m))
+		for i := range m {
+			words[i] = string(m[i].Word)
+		}
+		return words
+	}
+
+	tests := []struct {
+		s        string
+		opts     SuggestOptions
+		expected []string
+	}{
+		{"toad", SuggestOptions{Distance: 1},
+			[]string{"toad", "load", "toads", "tod", "toda", "todd"}},
+		{"toad", SuggestOptions{Distance: 1, Edits: Deletions | Additions},
+			[]string{"toad", "toads", "tod"}},
+		// Substituting costs 2, so substitutions need a larger distance
+		{"toad", SuggestOptions{Distance: 1, SubstitutionCost: 2},
+			[]string{"toad", "toads", "tod", "toda"}},
+		{"toad", SuggestOptions{Distance: 2, SubstitutionCost: 2, AdditionCost: 3},
+			[]string{"toad", "tod", "toda", "load", "todd"}},
+		{"toad", SuggestOptions{Distance: 1, Limit: 3}, []string{"toad", "load", "toads"}},
+		{"toad", SuggestOptions{Distance: 2, Limit: 2, Rank: func(m Matches) sort.Interface {
+			return sort.Reverse(ByDistance{m})
+		}}, []string{"today", "tea"}},
+		{"PARIS", SuggestOptions{Distance: 0}, []string{}},
+		{"PARIS", SuggestOptions{Distance: 0, IgnoreCase: true}, []string{"Paris"}},
+		{"THE", SuggestOptions{Distance: 1, IgnoreCase: true}, []string{"the"}},
+		{"HTE", SuggestOptions{Distance: 1, IgnoreCase: true, Edits: Transpositions},
+			[]string{"the"}},
+	}
+	for _, test := range tests {
+		for name, suggestions := range map[string]Matches{
+			"Trie":  trie.Suggest(test.s, test.opts),
+			"Radix": trie.Compact().Suggest(test.s, test.opts),
+		} {
+			if got := words(suggestions); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("%v: Suggest(%q, %+v) = %v, expected %v",
+					name, test.s, test.opts, got, test.expected)
+			}
+		}
+	}
+
+	// Distances are the total cost of the edits
+	for _, m := range trie.Suggest("toad", SuggestOptions{Distance: 5, SubstitutionCost: 2}) {
+		if string(m.Word) == "load" && m.Distance != 2 {
+			t.Errorf("'load' should cost 2, got %d", m.Distance)
+		}
+	}
+
+	// The presets match the options they stand for
+	if !reflect.DeepEqual(trie.Deletions("toads", 1),
+		trie.Suggest("toads", SuggestOptions{Distance: 1, Edits: Deletions}).Strings()) {
+		t.Error("Deletions should be a preset of Suggest")
+	}
+}
+
+// The cheapest way to turn a into b by optimal string alignment with the
+// costs of opts
+func weightedDistance(a, b []rune, opts SuggestOptions) int {
+	cost := func(c int) int {
+		if c == 0 {
+			return 1
+		}
+		return c
+	}
+	k := opts.Keyboard
+	del := func(i int) int {
+		if k != nil && (i > 1 && k.slip(a[i-2], a[i-1]) || i < len(a) && k.slip(a[i], a[i-1])) {
+			return min(cost(opts.DeletionCost), cost(opts.KeyboardCost))
+		}
+		return cost(opts.DeletionCost)
+	}
+	add := func(i int, c rune) int {
+		if k != nil && (i > 0 && k.slip(a[i-1], c) || i < len(a) && k.slip(a[i], c)) {
+			return min(cost(opts.AdditionCost), cost(opts.KeyboardCost))
+		}
+		return cost(opts.AdditionCost)
+	}
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		if i > 0 {
+			d[i][0] = d[i-1][0] + del(i)
+		}
+	}
+	for j := 1; j <= len(b); j++ {
+		d[0][j] = d[0][j-1] + add(0, b[j-1])
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			sub := 0
+			if a[i-1] != b[j-1] {
+				var ok bool
+				if sub, ok = opts.SubstitutionCosts.Cost(a[i-1], b[j-1]); !ok {
+					sub = cost(opts.SubstitutionCost)
+					if k != nil && k.Adjacent(a[i-1], b[j-1]) {
+						sub = min(sub, cost(opts.KeyboardCost))
+					}
+				}
+			}
+			d[i][j] = min(d[i-1][j]+del(i),
+				d[i][j-1]+add(i, b[j-1]), d[i-1][j-1]+sub)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && a[i-1] != a[i-2] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+cost(opts.TranspositionCost))
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func TestSubstitutionMatrix(t *testing.T) {
+	trie := NewTrie()
+	for _, w := range []string{"bat", "bet", "bit", "cat", "kat"} {
+		trie.InsertString(w)
+	}
+	m := NewSubstitutionMatrix()
+	m.Set('a', 'e', 1)
+	m.SetOneWay('c', 'k', 0)
+	opts := SuggestOptions{Distance: 2, AdditionCost: 2, DeletionCost: 2,
+		SubstitutionCost: 2, TranspositionCost: 2, SubstitutionCosts: m}
+
+	suggestions := trie.Suggest("bat", opts)
+	if got := suggestions.Strings(); !reflect.DeepEqual(got, []string{"bat", "bet", "bit", "cat", "kat"}) {
+		t.Errorf("Confusable pairs should rank first, got %v", got)
+	}
+	if got := trie.Suggest("cat", SuggestOptions{Distance: 0, SubstitutionCosts: m}).Strings(); !reflect.DeepEqual(got, []string{"cat", "kat"}) {
+		t.Errorf("A free substitution should be a distance of 0, got %v", got)
+	}
+	if got := trie.Suggest("kat", SuggestOptions{Distance: 0, SubstitutionCosts: m}).Strings(); !reflect.DeepEqual(got, []string{"kat"}) {
+		t.Errorf("One way substitutions shouldn't apply in reverse, got %v", got)
+	}
+	if cost, ok := m.Cost('e', 'a'); !ok || cost != 1 {
+		t.Error("Set should apply both ways")
+	}
+	m.Set('b', 'p', -3)
+	if cost, _ := m.Cost('p', 'b'); cost != 0 {
+		t.Errorf("Negative costs should be 0, got %d", cost)
+	}
+}
+
+func TestWeightedSearchMatchesEditDistance(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	word := func() []rune {
+		w := make([]rune, 1+rng.Intn(6))
+		for i := range w {
+			w[i] = rune('a' + rng.Intn(4))
+		}
+		return w
+	}
+	trie := NewTrie()
+	words := map[string]bool{}
+	for i := 0; i < 300; i++ {
+		w := string(word())
+		words[w] = true
+		trie.InsertString(w)
+	}
+	check := func(r []rune, opts SuggestOptions) {
+		t.Helper()
+		found := map[string]int{}
+		for _, m := range trie.Suggest(string(r), opts) {
+			found[string(m.Word)] = m.Distance
+		}
+		for w := range words {
+			d := weightedDistance(r, runes(w), opts)
+			// The cost of a word found only by sound, whatever the distance
+			if cost := opts.cost(r, w); cost != d {
+				t.Errorf("%q -> %q with %+v: want cost %d, got %d", string(r), w, opts, d, cost)
+			}
+			got, ok := found[w]
+			if d <= opts.Distance && (!ok || got != d) || d > opts.Distance && ok {
+				t.Errorf("%q -> %q with %+v: want distance %d, got %d (found %t)",
+					string(r), w, opts, d, got, ok)
+			}
+		}
+	}
+
+	// A transposition cheaper than the substitutions it replaces
+	words["ba"] = true
+	trie.InsertString("ba")
+	check(runes("ab"), SuggestOptions{Distance: 1, AdditionCost: 2, DeletionCost: 2,
+		SubstitutionCost: 5, TranspositionCost: 1})
+
+	for i := 0; i < 30; i++ {
+		m := NewSubstitutionMatrix()
+		for k := 0; k < 4; k++ {
+			m.SetOneWay(rune('a'+rng.Intn(4)), rune('a'+rng.Intn(4)), rng.Intn(4))
+		}
+		opts := SuggestOptions{
+			Distance:          rng.Intn(6),
+			AdditionCost:      rng.Intn(4),
+			DeletionCost:      rng.Intn(4),
+			SubstitutionCost:  rng.Intn(4),
+			TranspositionCost: rng.Intn(4),
+			SubstitutionCosts: m,
+		}
+		if rng.Intn(2) == 0 {
+			opts.Keyboard = QWERTY
+			opts.KeyboardCost = rng.Intn(3)
+		}
+		check(word(), opts)
+	}
+}
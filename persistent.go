@@ -0,0 +1,186 @@
+package gospell
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// A PersistentTrie is an immutable Trie. Inserting or removing a word returns
+// a new version that shares every node off the word's path with the old one,
+// so old versions stay valid and unchanged, and making a new version costs
+// only the length of the word. Every version is safe for concurrent use.
+type PersistentTrie struct {
+	lexicon[*persistentNode]
+}
+
+type persistentNode struct {
+	edges    []persistentEdge // Sorted by rune
+	leaf     bool
+	weight   int
+	heaviest int // The weight of the heaviest word under the node
+}
+
+type persistentEdge struct {
+	r  rune
+	to *persistentNode
+}
+
+// Create a new, empty PersistentTrie
+func NewPersistentTrie() *PersistentTrie {
+	return &PersistentTrie{lexicon[*persistentNode]{new(persistentNode)}}
+}
+
+// Copy the Trie into a PersistentTrie, such as to share a dictionary loaded
+// with TrieFromFile between many versions
+func (t *Trie) Snapshot() *PersistentTrie {
+	return &PersistentTrie{lexicon[*persistentNode]{snapshot(t)}}
+}
+
+func snapshot(t *Trie) *persistentNode {
+	n := &persistentNode{leaf: t.leaf, weight: t.weight}
+	for r, child := range t.children {
+		if child != nil {
+			n.edges = append(n.edges, persistentEdge{r, snapshot(child)})
+		}
+	}
+	sort.Slice(n.edges, func(i, j int) bool { return n.edges[i].r < n.edges[j].r })
+	n.reweigh()
+	return n
+}
+
+// Compute the heaviest weight of a node from its children
+func (n *persistentNode) reweigh() {
+	n.heaviest = -infinity
+	if n.leaf {
+		n.heaviest = n.weight
+	}
+	for _, e := range n.edges {
+		n.heaviest = max(n.heaviest, e.to.heaviest)
+	}
+}
+
+// Find the index of the edge for r, or where it would be inserted
+func (n *persistentNode) find(r rune) (int, bool) {
+	i := sort.Search(len(n.edges), func(i int) bool { return n.edges[i].r >= r })
+	return i, i < len(n.edges) && n.edges[i].r == r
+}
+
+// Return a copy of n with the word inserted
+func (n *persistentNode) insert(word []rune, weight int, weighted bool) *persistentNode {
+	copied := *n
+	if len(word) == 0 {
+		copied.leaf = true
+		if weighted {
+			copied.weight = weight
+		}
+		copied.reweigh()
+		return &copied
+	}
+
+	i, found := n.find(word[0])
+	child := new(persistentNode)
+	if found {
+		child = n.edges[i].to
+	}
+	edge := persistentEdge{word[0], child.insert(word[1:], weight, weighted)}
+	copied.edges = make([]persistentEdge, 0, len(n.edges)+1)
+	copied.edges = append(copied.edges, n.edges[:i]...)
+	copied.edges = append(copied.edges, edge)
+	if found {
+		i++
+	}
+	copied.edges = append(copied.edges, n.edges[i:]...)
+	copied.reweigh()
+	return &copied
+}
+
+// Return a copy of n with the word removed, or nil if the copy would be
+// empty. Returns false, and n itself, if the word wasn't found.
+func (n *persistentNode) remove(word []rune) (*persistentNode, bool) {
+	copied := *n
+	if len(word) == 0 {
+		if !n.leaf {
+			return n, false
+		}
+		copied.leaf = false
+		copied.weight = 0
+	} else {
+		i, found := n.find(word[0])
+		if !found {
+			return n, false
+		}
+		child, removed := n.edges[i].to.remove(word[1:])
+		if !removed {
+			return n, false
+		}
+		copied.edges = append([]persistentEdge{}, n.edges...)
+		if child == nil {
+			copied.edges = append(copied.edges[:i], copied.edges[i+1:]...)
+		} else {
+			copied.edges[i].to = child
+		}
+	}
+	if !copied.leaf && len(copied.edges) == 0 {
+		return nil, true
+	}
+	copied.reweigh()
+	return &copied, true
+}
+
+// Return a new version of the PersistentTrie with s inserted
+func (p *PersistentTrie) InsertString(s string) *PersistentTrie {
+	return &PersistentTrie{lexicon[*persistentNode]{p.root.insert(runes(s), 0, false)}}
+}
+
+// Return a new version of the PersistentTrie with s inserted with a weight.
+// See Trie.InsertWeighted.
+func (p *PersistentTrie) InsertStringWeighted(s string, weight int) *PersistentTrie {
+	return &PersistentTrie{lexicon[*persistentNode]{p.root.insert(runes(s), weight, true)}}
+}
+
+// Return a new version of the PersistentTrie without s, and whether s was
+// Contained. If it wasn't, p itself is returned.
+func (p *PersistentTrie) RemoveString(s string) (*PersistentTrie, bool) {
+	if s == "" {
+		return p, false
+	}
+	root, removed := p.root.remove(runes(s))
+	if !removed {
+		return p, false
+	}
+	if root == nil {
+		root = new(persistentNode)
+	}
+	return &PersistentTrie{lexicon[*persistentNode]{root}}, true
+}
+
+func (n *persistentNode) heaviestWeight() int { return n.heaviest }
+
+func (n *persistentNode) terminal() (bool, int) { return n.leaf, n.weight }
+
+func (n *persistentNode) child(r rune) node {
+	if i, found := n.find(r); found {
+		return n.edges[i].to
+	}
+	return nil
+}
+
+func (n *persistentNode) each(fn func(rune, node) bool) bool {
+	for _, e := range n.edges {
+		if !fn(e.r, e.to) {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the PersistentTrie at the end of a strings.Reader
+func (p *PersistentTrie) Get(s *strings.Reader) *PersistentTrie {
+	rest, _ := io.ReadAll(s)
+	n := get(p.root, string(rest))
+	if n == nil {
+		return nil
+	}
+	return &PersistentTrie{lexicon[*persistentNode]{n.(*persistentNode)}}
+}
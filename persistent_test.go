@@ -0,0 +1,82 @@
+package gospell
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPersistentTrie(t *testing.T) {
+	base := testTrie().Snapshot()
+	if !HasEveryElement(t, base.AllFullChildren(), testTrie().AllFullChildren()) {
+		t.Error(base.AllFullChildren())
+	}
+	if base.Weight("the") != 500 {
+		t.Errorf("Weight of 'the' is %d", base.Weight("the"))
+	}
+
+	v1 := base.InsertStringWeighted("toadstool", 7)
+	v2 := v1.InsertString("gospell")
+	if base.ContainsString("toadstool") || v1.ContainsString("gospell") {
+		t.Error("Inserting shouldn't change earlier versions")
+	}
+	if !v2.ContainsString("toadstool") || v2.Weight("toadstool") != 7 ||
+		!v2.Contains(strings.NewReader("gospell")) {
+		t.Error("New versions should contain the inserted words")
+	}
+
+	// Only the path to the new word is copied
+	if base.root.child('r') != v2.root.child('r') {
+		t.Error("Untouched subtrees should be shared")
+	}
+	if base.root.child('t') == v1.root.child('t') {
+		t.Error("The path to an inserted word should be copied")
+	}
+
+	v3, removed := v2.RemoveString("toadstool")
+	if !removed || v3.ContainsString("toadstool") || !v2.ContainsString("toadstool") {
+		t.Error("Removing should only change the new version")
+	}
+	if len(v3.Get(strings.NewReader("toads")).root.edges) != 0 {
+		t.Error("Empty branches should be pruned")
+	}
+	if same, removed := v3.RemoveString("toadstool"); removed || same != v3 {
+		t.Error("Removing a missing word should return the same version")
+	}
+
+	empty := NewPersistentTrie().InsertString("a")
+	empty, _ = empty.RemoveString("a")
+	if len(empty.AllFullChildren()) != 0 || empty.ContainsString("a") {
+		t.Error("Removing every word should leave an empty PersistentTrie")
+	}
+
+	suggestions := v2.SuggestWords("gospel", 1)
+	if len(suggestions) != 1 || suggestions[0] != "gospell" {
+		t.Errorf("Wrong suggestions %v", suggestions)
+	}
+}
+
+// Readers of one version are unaffected by writers making new ones. Run with
+// -race to check for data races.
+func TestPersistentTrieSnapshots(t *testing.T) {
+	base := testTrie().Snapshot()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p := base
+		for _, w := range []string{"toadish", "toadlet", "toady", "toadies"} {
+			p = p.InsertString(w)
+		}
+		p, _ = p.RemoveString("toad")
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if s := base.SuggestWords("toad", 1); len(s) != 6 || s[0] != "toad" {
+				t.Errorf("Snapshot changed: %v", s)
+			}
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,362 @@
+package gospell
+
+import (
+	"sort"
+	"strings"
+)
+
+// A PhoneticEncoder gives the codes for how a word sounds. Words sound alike
+// when they share a code.
+type PhoneticEncoder func(word string) []string
+
+// The built-in PhoneticEncoders
+var (
+	SoundexEncoder   PhoneticEncoder = func(word string) []string { return codes(Soundex(word)) }
+	MetaphoneEncoder PhoneticEncoder = func(word string) []string { return codes(Metaphone(word)) }
+	// Both the primary and alternate codes, so that words sound alike if
+	// either pronunciation of one matches either of the other
+	DoubleMetaphoneEncoder PhoneticEncoder = func(word string) []string {
+		return codes(DoubleMetaphone(word))
+	}
+)
+
+// The distinct, non-empty codes
+func codes(all ...string) []string {
+	found := []string{}
+	for _, c := range all {
+		if c != "" && (len(found) == 0 || found[0] != c) {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// The letters of a word in upper case, dropping anything outside A to Z
+func asciiLetters(word string) []byte {
+	letters := []byte{}
+	for _, r := range strings.ToUpper(word) {
+		if 'A' <= r && r <= 'Z' {
+			letters = append(letters, byte(r))
+		}
+	}
+	return letters
+}
+
+// The Soundex digit of each letter from A to Z. Vowels are 0 and separate
+// letters with the same digit, while H and W (-) don't.
+const soundexDigits = "0123012-02245501262301-202"
+
+// Encode how a word sounds with American Soundex, as a letter and three
+// digits, e.g. "Robert" and "Rupert" are both R163. Only the letters A to Z
+// are encoded, so a word without any has the code "".
+func Soundex(word string) string {
+	letters := asciiLetters(word)
+	if len(letters) == 0 {
+		return ""
+	}
+	code := []byte{letters[0]}
+	last := soundexDigits[letters[0]-'A']
+	for _, c := range letters[1:] {
+		switch digit := soundexDigits[c-'A']; digit {
+		case '0':
+			last = digit
+		case '-':
+		default:
+			if digit != last {
+				code = append(code, digit)
+			}
+			last = digit
+		}
+		if len(code) == 4 {
+			break
+		}
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// Metaphone codes are at most this long
+const metaphoneLength = 4
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("AEIOU", c) >= 0
+}
+
+// Encode how a word sounds with Lawrence Philips' original Metaphone
+// algorithm, e.g. "physics" and "fizzix" are both FSKS. A code has up to
+// four letters, with 0 standing for "th". Only the letters A to Z are
+// encoded.
+func Metaphone(word string) string {
+	w := asciiLetters(word)
+	switch {
+	case len(w) == 0:
+		return ""
+	case len(w) == 1:
+		return string(w)
+	}
+
+	// Silent or changed first letters
+	switch {
+	case string(w[:2]) == "AE" || w[1] == 'N' && strings.IndexByte("GKP", w[0]) >= 0 ||
+		string(w[:2]) == "WR":
+		w = w[1:]
+	case string(w[:2]) == "WH":
+		w = append([]byte{'W'}, w[2:]...)
+	case w[0] == 'X':
+		w[0] = 'S'
+	}
+
+	// The letter at i, or 0 outside the word
+	at := func(i int) byte {
+		if i < 0 || i >= len(w) {
+			return 0
+		}
+		return w[i]
+	}
+	is := func(i int, s string) bool {
+		return i >= 0 && i+len(s) <= len(w) && string(w[i:i+len(s)]) == s
+	}
+	front := func(i int) bool {
+		return at(i) != 0 && strings.IndexByte("EIY", at(i)) >= 0
+	}
+	last := len(w) - 1
+
+	code := []byte{}
+	for i := 0; i < len(w) && len(code) < metaphoneLength; i++ {
+		c := w[i]
+		if c != 'C' && at(i-1) == c {
+			// Doubled letters sound once
+			continue
+		}
+		switch c {
+		case 'A', 'E', 'I', 'O', 'U':
+			if i == 0 {
+				code = append(code, c)
+			}
+		case 'B':
+			// Silent in a final "mb", e.g. "dumb"
+			if !(at(i-1) == 'M' && i == last) {
+				code = append(code, 'B')
+			}
+		case 'C':
+			switch {
+			case at(i-1) == 'S' && front(i+1):
+				// Silent in "sci", "sce" and "scy"
+			case is(i, "CIA"):
+				code = append(code, 'X')
+			case front(i + 1):
+				code = append(code, 'S')
+			case at(i-1) == 'S' && at(i+1) == 'H':
+				code = append(code, 'K')
+			case at(i+1) == 'H':
+				if i == 0 && len(w) >= 3 && isVowel(w[2]) {
+					code = append(code, 'K')
+				} else {
+					code = append(code, 'X')
+				}
+			default:
+				code = append(code, 'K')
+			}
+		case 'D':
+			if at(i+1) == 'G' && front(i+2) {
+				// e.g. "edge"
+				code = append(code, 'J')
+				i += 2
+			} else {
+				code = append(code, 'T')
+			}
+		case 'G':
+			switch {
+			case at(i+1) == 'H' && (i+1 == last || !isVowel(at(i+2))):
+				// Silent in "gh" but not before a vowel, e.g. "night"
+			case i > 0 && (is(i, "GN") && i+1 == last || is(i, "GNED") && i+3 == last):
+				// Silent in a final "gn" or "gned", e.g. "sign"
+			case front(i+1) && at(i-1) != 'G':
+				code = append(code, 'J')
+			default:
+				code = append(code, 'K')
+			}
+		case 'H':
+			// Kept only before a vowel and not after c, s, p, t or g
+			if i < last && !(i > 0 && strings.IndexByte("CSPTG", w[i-1]) >= 0) &&
+				isVowel(at(i+1)) {
+				code = append(code, 'H')
+			}
+		case 'F', 'J', 'L', 'M', 'N', 'R':
+			code = append(code, c)
+		case 'K':
+			// Silent after c
+			if at(i-1) != 'C' {
+				code = append(code, 'K')
+			}
+		case 'P':
+			if at(i+1) == 'H' {
+				code = append(code, 'F')
+			} else {
+				code = append(code, 'P')
+			}
+		case 'Q':
+			code = append(code, 'K')
+		case 'S':
+			if is(i, "SH") || is(i, "SIO") || is(i, "SIA") {
+				code = append(code, 'X')
+			} else {
+				code = append(code, 'S')
+			}
+		case 'T':
+			switch {
+			case is(i, "TIA") || is(i, "TIO"):
+				code = append(code, 'X')
+			case is(i, "TCH"):
+				// Silent, as the "ch" sounds
+			case is(i, "TH"):
+				code = append(code, '0')
+			default:
+				code = append(code, 'T')
+			}
+		case 'V':
+			code = append(code, 'F')
+		case 'W', 'Y':
+			// Only before a vowel
+			if i < last && isVowel(at(i+1)) {
+				code = append(code, c)
+			}
+		case 'X':
+			code = append(code, 'K', 'S')
+		case 'Z':
+			code = append(code, 'S')
+		}
+	}
+	if len(code) > metaphoneLength {
+		code = code[:metaphoneLength]
+	}
+	return string(code)
+}
+
+// A PhoneticIndex groups the words of a Lexicon by how they sound, so that
+// misspellings which are too many edits away, such as "fizzix" for
+// "physics", can still be suggested, like the "sounds-like" suggestions of
+// Aspell. Set it as SuggestOptions.Phonetic, or use its own Suggest.
+//
+// A PhoneticIndex doesn't follow changes to its Lexicon: every word inserted
+// into or removed from the Lexicon after NewPhoneticIndex must also be passed
+// to the index's Insert or Remove. Removed words that are still indexed are
+// skipped, but inserted words that aren't are never suggested by sound. A
+// PhoneticIndex isn't safe to change while other goroutines use it.
+type PhoneticIndex struct {
+	words  Lexicon
+	encode PhoneticEncoder
+	codes  map[string][]string
+}
+
+// Index every word of a Lexicon by the codes of encode, such as
+// DoubleMetaphoneEncoder
+func NewPhoneticIndex(words Lexicon, encode PhoneticEncoder) *PhoneticIndex {
+	p := &PhoneticIndex{words: words, encode: encode, codes: map[string][]string{}}
+	walk(words.rootNode(), nil, func(word []rune, weight int) bool {
+		p.Insert(string(word))
+		return true
+	})
+	return p
+}
+
+// Index a word added to the Lexicon
+func (p *PhoneticIndex) Insert(word string) {
+	for _, c := range p.encode(word) {
+		words := p.codes[c]
+		if i := sort.SearchStrings(words, word); i == len(words) || words[i] != word {
+			words = append(words, "")
+			copy(words[i+1:], words[i:])
+			words[i] = word
+			p.codes[c] = words
+		}
+	}
+}
+
+// Forget a word removed from the Lexicon
+func (p *PhoneticIndex) Remove(word string) {
+	for _, c := range p.encode(word) {
+		words := p.codes[c]
+		if i := sort.SearchStrings(words, word); i < len(words) && words[i] == word {
+			words = append(words[:i], words[i+1:]...)
+			if len(words) == 0 {
+				delete(p.codes, c)
+			} else {
+				p.codes[c] = words
+			}
+		}
+	}
+}
+
+// Get the indexed words sharing a code with s, sorted
+func (p *PhoneticIndex) SoundsLike(s string) []string {
+	found := []string{}
+	for _, c := range p.encode(s) {
+		found = append(found, p.codes[c]...)
+	}
+	sort.Strings(found)
+	return deduplicate(found)
+}
+
+func deduplicate(sorted []string) []string {
+	unique := sorted[:0]
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// Return the suggestions of the indexed Lexicon's Suggest, adding the words
+// which sound like s. See SuggestOptions.Phonetic.
+func (p *PhoneticIndex) Suggest(s string, opts SuggestOptions) Matches {
+	opts.Phonetic = p
+	return suggestWith(p.words.rootNode(), runes(s), opts)
+}
+
+// Return the spelling suggestions of SuggestWords, followed by the words
+// which sound like s but are further away, e.g. "fizzix" suggests "physics"
+func (p *PhoneticIndex) SuggestWords(s string, distance int) []string {
+	return p.Suggest(s, SuggestOptions{Distance: distance}).Strings()
+}
+
+// Add the words under root sounding like r to suggestions, marking them
+// Phonetic. A word found only by sound has the Distance of its cheapest
+// edits from r, however many, so it ranks after closer words.
+func (p *PhoneticIndex) addSoundsLike(root node, r []rune, opts SuggestOptions, suggestions Matches) Matches {
+	found := map[string]int{}
+	for i, m := range suggestions {
+		found[string(m.Word)] = i
+	}
+	for _, word := range p.SoundsLike(string(r)) {
+		if i, ok := found[word]; ok {
+			suggestions[i].Phonetic = true
+			continue
+		}
+		n := get(root, word)
+		if n == nil {
+			continue
+		}
+		leaf, weight := n.terminal()
+		if !leaf {
+			continue
+		}
+		suggestions = append(suggestions, Match{
+			Word:     runes(word),
+			Distance: opts.cost(r, word),
+			Weight:   weight,
+			Phonetic: true,
+		})
+	}
+	return suggestions
+}
+
+// The cost under opts of the cheapest edits turning r into word, allowing
+// every kind of edit
+func (o SuggestOptions) cost(r []rune, word string) int {
+	o.Edits = AllEdits
+	return o.searcher(r, nil).cost(runes(word))
+}
@@ -0,0 +1,101 @@
+package gospell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSoundex(t *testing.T) {
+	tests := map[string]string{
+		"Robert":   "R163",
+		"Rupert":   "R163",
+		"Rubin":    "R150",
+		"Ashcraft": "A261",
+		"Tymczak":  "T522",
+		"Pfister":  "P236",
+		"a":        "A000",
+		"":         "",
+	}
+	for word, expected := range tests {
+		if code := Soundex(word); code != expected {
+			t.Errorf("%q should encode as %v, got %v", word, expected, code)
+		}
+	}
+}
+
+func TestMetaphone(t *testing.T) {
+	tests := map[string]string{
+		"physics": "FSKS",
+		"fizzix":  "FSKS",
+		"knight":  "NT",
+		"wright":  "RT",
+		"dumb":    "TM",
+		"thumb":   "0M",
+		"Xavier":  "SFR",
+		"edge":    "EJ",
+		"science": "SNS",
+		"":        "",
+	}
+	for word, expected := range tests {
+		if code := Metaphone(word); code != expected {
+			t.Errorf("%q should encode as %v, got %v", word, expected, code)
+		}
+	}
+}
+
+func TestPhoneticIndex(t *testing.T) {
+	trie := NewTrie()
+	for _, w := range []string{"physics", "fizz", "fix", "toad"} {
+		trie.InsertString(w)
+	}
+	trie.InsertStringWeighted("physics", 3)
+	index := NewPhoneticIndex(trie, DoubleMetaphoneEncoder)
+
+	if words := index.SoundsLike("fizzix"); !reflect.DeepEqual(words, []string{"physics"}) {
+		t.Errorf("Only 'physics' should sound like 'fizzix', got %v", words)
+	}
+	if words := trie.SuggestWords("fizzix", 2); !reflect.DeepEqual(words, []string{"fizz"}) {
+		t.Errorf("'physics' is too far to suggest by edits, got %v", words)
+	}
+	if words := index.SuggestWords("fizzix", 2); !reflect.DeepEqual(words, []string{"fizz", "physics"}) {
+		t.Errorf("'physics' should be suggested by sound, got %v", words)
+	}
+
+	trie.InsertString("fizzics")
+	index.Insert("fizzics")
+	expected := Matches{
+		{Word: runes("fizz"), Distance: 2},
+		{Word: runes("fizzics"), Distance: 2, Phonetic: true},
+		{Word: runes("physics"), Distance: 6, Weight: 3, Phonetic: true},
+	}
+	suggestions := trie.Suggest("fizzix", SuggestOptions{Distance: 2, Phonetic: index})
+	if len(suggestions) != len(expected) {
+		t.Fatalf("Wrong suggestions %v", suggestions)
+	}
+	for i := range expected {
+		if !suggestions[i].Equal(expected[i]) {
+			t.Errorf("Expected %v at %d, got %+v", expected[i], i, suggestions[i])
+		}
+	}
+	limited := index.Suggest("fizzix", SuggestOptions{Distance: 2, Limit: 2})
+	if len(limited) != 2 || !limited[1].Equal(expected[1]) {
+		t.Errorf("Limit should apply after adding words by sound, got %v", limited)
+	}
+
+	// Words sounding alike but missing from the searched Lexicon are skipped
+	other := NewTrie()
+	other.InsertString("fizz")
+	if words := other.Suggest("fizzix", SuggestOptions{Distance: 2, Phonetic: index}).Strings(); !reflect.DeepEqual(words, []string{"fizz"}) {
+		t.Errorf("Only words of the searched Lexicon should be suggested, got %v", words)
+	}
+
+	index.Remove("physics")
+	if words := index.SoundsLike("fizzix"); !reflect.DeepEqual(words, []string{"fizzics"}) {
+		t.Errorf("'physics' should be forgotten, got %v", words)
+	}
+
+	soundex := NewPhoneticIndex(trie, SoundexEncoder)
+	if words := soundex.SoundsLike("fiks"); !reflect.DeepEqual(words, []string{"fix", "fizz"}) {
+		t.Errorf("Soundex should match 'fix' and 'fizz', got %v", words)
+	}
+}
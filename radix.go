@@ -0,0 +1,211 @@
+package gospell
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// A Radix is a compacted Trie: every chain of nodes with a single child and no
+// word ending in it is merged into one node, whose label holds the runes of
+// the whole chain. It supports the same lookups and suggestions as a Trie
+// with far fewer nodes.
+type Radix struct {
+	lexicon[radixNode]
+}
+
+type radixTree struct {
+	label    []rune       // The runes leading to this node from its parent
+	children []*radixTree // Sorted by the first rune of their labels
+	leaf     bool
+	weight   int
+	heaviest int // The weight of the heaviest word under the node
+}
+
+// Create a new, empty Radix
+func NewRadix() *Radix {
+	return &Radix{lexicon[radixNode]{radixNode{new(radixTree), 0}}}
+}
+
+// Build a Radix holding the same words and weights as the Trie
+func (t *Trie) Compact() *Radix {
+	return &Radix{lexicon[radixNode]{radixNode{compact(t, nil), 0}}}
+}
+
+// Build the Radix node for t, reached by label. The root has no label, so
+// only nodes below it are merged.
+func compact(t *Trie, label []rune) *radixTree {
+	// Merge the chain of single children below t into the label
+	for label != nil && !t.leaf && len(t.children) == 1 {
+		for c, child := range t.children {
+			label = append(label, c)
+			t = child
+		}
+	}
+	n := &radixTree{label: label, leaf: t.leaf, weight: t.weight}
+	for c, child := range t.children {
+		if child != nil {
+			n.children = append(n.children, compact(child, []rune{c}))
+		}
+	}
+	sort.Slice(n.children, func(i, j int) bool {
+		return n.children[i].label[0] < n.children[j].label[0]
+	})
+	n.reweigh()
+	return n
+}
+
+// Recompute the heaviest weight of n from its word and children
+func (n *radixTree) reweigh() {
+	n.heaviest = -infinity
+	if n.leaf {
+		n.heaviest = n.weight
+	}
+	for _, c := range n.children {
+		n.heaviest = max(n.heaviest, c.heaviest)
+	}
+}
+
+// Find the index of the child whose label starts with r, or where it would
+// be inserted
+func (n *radixTree) find(r rune) (int, bool) {
+	i := sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].label[0] >= r
+	})
+	return i, i < len(n.children) && n.children[i].label[0] == r
+}
+
+func (n *radixTree) insert(word []rune, weight int, weighted bool) {
+	// Reweigh the path once the word is in place, as its weight may have
+	// changed in either direction
+	path := []*radixTree{}
+	defer func() {
+		for i := len(path) - 1; i >= 0; i-- {
+			path[i].reweigh()
+		}
+	}()
+	for len(word) > 0 {
+		path = append(path, n)
+		i, found := n.find(word[0])
+		if !found {
+			child := &radixTree{label: word, leaf: true, weight: weight, heaviest: weight}
+			n.children = append(n.children, nil)
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = child
+			return
+		}
+
+		child := n.children[i]
+		common := 0
+		for common < len(child.label) && common < len(word) &&
+			child.label[common] == word[common] {
+			common++
+		}
+		if common < len(child.label) {
+			// The word leaves the label part way along, so split the child
+			split := &radixTree{
+				label:    child.label[:common:common],
+				children: []*radixTree{child},
+				heaviest: child.heaviest,
+			}
+			child.label = child.label[common:]
+			n.children[i] = split
+			child = split
+		}
+		n = child
+		word = word[common:]
+	}
+	path = append(path, n)
+	n.leaf = true
+	if weighted {
+		n.weight = weight
+	}
+}
+
+// Insert a strings.Reader into the Radix
+func (n *Radix) Insert(s *strings.Reader) {
+	rest, _ := io.ReadAll(s)
+	n.root.n.insert(runes(string(rest)), 0, false)
+}
+
+// Insert a string into the Radix
+func (n *Radix) InsertString(s string) {
+	n.root.n.insert(runes(s), 0, false)
+}
+
+// Insert a strings.Reader into the Radix with a weight. See
+// Trie.InsertWeighted.
+func (n *Radix) InsertWeighted(s *strings.Reader, weight int) {
+	rest, _ := io.ReadAll(s)
+	n.root.n.insert(runes(string(rest)), weight, true)
+}
+
+// Insert a string into the Radix with a weight. See Trie.InsertWeighted.
+func (n *Radix) InsertStringWeighted(s string, weight int) {
+	n.root.n.insert(runes(s), weight, true)
+}
+
+// A position in a Radix: i runes along the label leading to n
+type radixNode struct {
+	n *radixTree
+	i int
+}
+
+func (p radixNode) terminal() (bool, int) {
+	if p.i < len(p.n.label) {
+		return false, 0
+	}
+	return p.n.leaf, p.n.weight
+}
+
+// Every word under a position along a label is under the node it leads to
+func (p radixNode) heaviestWeight() int { return p.n.heaviest }
+
+func (p radixNode) child(r rune) node {
+	if p.i < len(p.n.label) {
+		if p.n.label[p.i] == r {
+			return radixNode{p.n, p.i + 1}
+		}
+		return nil
+	}
+	if i, found := p.n.find(r); found {
+		return radixNode{p.n.children[i], 1}
+	}
+	return nil
+}
+
+func (p radixNode) each(fn func(rune, node) bool) bool {
+	if p.i < len(p.n.label) {
+		return fn(p.n.label[p.i], radixNode{p.n, p.i + 1})
+	}
+	for _, c := range p.n.children {
+		if !fn(c.label[0], radixNode{c, 1}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the Radix at the end of a strings.Reader. It shares nodes with n, and
+// if the prefix ends part way along a label it should only be read.
+func (n *Radix) Get(s *strings.Reader) *Radix {
+	rest, _ := io.ReadAll(s)
+	found := get(n.root, string(rest))
+	if found == nil {
+		return nil
+	}
+	return &Radix{lexicon[radixNode]{found.(radixNode)}}
+}
+
+// Count the nodes in the Radix, including its root
+func (n *Radix) nodeCount() int {
+	return n.root.n.nodeCount()
+}
+
+func (n *radixTree) nodeCount() int {
+	count := 1
+	for _, c := range n.children {
+		count += c.nodeCount()
+	}
+	return count
+}
@@ -0,0 +1,166 @@
+package gospell
+
+import (
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRadixInsert(t *testing.T) {
+	s1 := "Salmon"
+	s2 := "Salmonella"
+
+	radix := NewRadix()
+	radix.Insert(strings.NewReader(s2))
+	radix.InsertString(s1)
+	radix.InsertStringWeighted("Salt", 3)
+	if !radix.ContainsString(s1) || !radix.Contains(strings.NewReader(s2)) {
+		t.Error("Inserted words should be Contained")
+	}
+	if radix.ContainsString("Salmo") || radix.ContainsString("Sal") {
+		t.Error("Shouldn't contain part of the word.")
+	}
+	if radix.Weight("Salt") != 3 || radix.Weight("Salmon") != 0 {
+		t.Errorf("Wrong weights %d %d", radix.Weight("Salt"), radix.Weight("Salmon"))
+	}
+	// "Sal" is shared, then "mon" and "t", then "ella"
+	if n := radix.nodeCount(); n != 5 {
+		t.Errorf("Expected 5 nodes, found %d", n)
+	}
+
+	if !HasEveryElement(t, radix.Get(strings.NewReader("Salmo")).AllFullChildren(), []string{"n", "nella"}) {
+		t.Error(radix.Get(strings.NewReader("Salmo")).AllFullChildren())
+	}
+	if !HasEveryElement(t, radix.Get(strings.NewReader(s1)).AllFullChildren(), []string{"ella"}) {
+		t.Error(radix.Get(strings.NewReader(s1)).AllFullChildren())
+	}
+	if radix.Get(strings.NewReader("abcdefg")) != nil {
+		t.Error("This Radix shouldn't exist!")
+	}
+}
+
+// A Radix, whether compacted from a Trie or built by inserting, must behave
+// exactly like the Trie
+func TestRadixMatchesTrie(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	word := func() string {
+		w := make([]rune, 1+rng.Intn(8))
+		for i := range w {
+			w[i] = []rune("abcd狐犬")[rng.Intn(6)]
+		}
+		return string(w)
+	}
+
+	trie := NewTrie()
+	inserted := NewRadix()
+	for i := 0; i < 500; i++ {
+		w := word()
+		weight := rng.Intn(10)
+		trie.InsertStringWeighted(w, weight)
+		inserted.InsertStringWeighted(w, weight)
+	}
+	compacted := trie.Compact()
+
+	for _, radix := range []*Radix{compacted, inserted} {
+		if !HasEveryElement(t, radix.AllFullChildren(), trie.AllFullChildren()) {
+			t.Errorf("Expected %v, got %v", trie.AllFullChildren(), radix.AllFullChildren())
+		}
+		if radix.nodeCount() >= trie.nodeCount() {
+			t.Errorf("%d Radix nodes isn't fewer than %d Trie nodes",
+				radix.nodeCount(), trie.nodeCount())
+		}
+		for i := 0; i < 20; i++ {
+			s := word()
+			if radix.ContainsString(s) != trie.ContainsString(s) || radix.Weight(s) != trie.Weight(s) {
+				t.Errorf("%q: Radix and Trie disagree", s)
+			}
+			want := strings.Join(trie.SuggestWords(s, 2), ",")
+			if got := strings.Join(radix.SuggestWords(s, 2), ","); got != want {
+				t.Errorf("SuggestWords(%q): expected %v, got %v", s, want, got)
+			}
+			want = strings.Join(trie.Deletions(s, 1), ",")
+			if got := strings.Join(radix.Deletions(s, 1), ","); got != want {
+				t.Errorf("Deletions(%q): expected %v, got %v", s, want, got)
+			}
+			want = strings.Join(trie.Anagrams(s), ",")
+			if got := strings.Join(radix.Anagrams(s), ","); got != want {
+				t.Errorf("Anagrams(%q): expected %v, got %v", s, want, got)
+			}
+		}
+	}
+}
+
+// Report the node count and heap size of each layout
+func benchmarkLayout(b *testing.B, build func(*Trie) interface{ nodeCount() int }) {
+	b.StopTimer()
+	trie, err := TrieFromFile("/usr/share/dict/words")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var before, after runtime.MemStats
+	var nodes int
+	var heap int64
+	for i := 0; i < b.N; i++ {
+		// The last iteration's dictionary must be collected before sampling,
+		// or it is counted in before and the difference can go negative
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		b.StartTimer()
+		dict := build(trie)
+		b.StopTimer()
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		nodes = dict.nodeCount()
+		heap = int64(after.HeapAlloc) - int64(before.HeapAlloc)
+		runtime.KeepAlive(dict)
+	}
+	b.ReportMetric(float64(nodes), "nodes")
+	b.ReportMetric(float64(heap), "heap-bytes")
+	runtime.KeepAlive(trie)
+}
+
+func BenchmarkLayoutTrie(b *testing.B) {
+	benchmarkLayout(b, func(trie *Trie) interface{ nodeCount() int } {
+		t := NewTrie()
+		for _, w := range trie.AllFullChildren() {
+			t.InsertString(w)
+		}
+		return t
+	})
+}
+
+func BenchmarkLayoutRadix(b *testing.B) {
+	benchmarkLayout(b, func(trie *Trie) interface{ nodeCount() int } {
+		return trie.Compact()
+	})
+}
+
+func BenchmarkRadixSuggestions1(b *testing.B) {
+	benchmarkRadixOp(b, func(radix *Radix, s string) { radix.SuggestWords(s, 1) })
+}
+
+func BenchmarkRadixSuggestions2(b *testing.B) {
+	benchmarkRadixOp(b, func(radix *Radix, s string) { radix.SuggestWords(s, 2) })
+}
+
+func benchmarkRadixOp(b *testing.B, op func(*Radix, string)) {
+	b.StopTimer()
+	trie, err := TrieFromFile("/usr/share/dict/words")
+	if err != nil {
+		b.Fatal(err)
+	}
+	radix := trie.Compact()
+
+	children := trie.AllFullChildren()
+	for i := 0; i < b.N; i++ {
+		for j, child := range children {
+			if j%1000 != 0 {
+				continue
+			}
+			b.StartTimer()
+			op(radix, child)
+			b.StopTimer()
+		}
+	}
+}
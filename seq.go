@@ -0,0 +1,123 @@
+//go:build go1.23
+
+package gospell
+
+import "iter"
+
+// Stream the complete words under n, without the path to n, in rune order
+func wordSeq(n node) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		walk(n, nil, func(word []rune, _ int) bool {
+			return yield(string(word))
+		})
+	}
+}
+
+// Stream the Matches of search as they are found
+func searchSeq(root node, r []rune, distance int, allowed Edits) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		newSearcher(r, distance, allowed, yield).walk(root)
+	}
+}
+
+// Stream the complete child words under this Trie node in rune order. See
+// Trie.AllFullChildren.
+func (t *Trie) AllFullChildrenSeq() iter.Seq[string] {
+	return wordSeq(t)
+}
+
+// Stream the Matches of Trie.Deletions as they are found, in no particular
+// order. Nothing is collected or sorted, so breaking out of the loop stops
+// the search.
+func (t *Trie) DeletionsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(t, runes(s), distance, Deletions)
+}
+
+// Stream the Matches of Trie.Additions. See Trie.DeletionsSeq.
+func (t *Trie) AdditionsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(t, runes(s), distance, Additions)
+}
+
+// Stream the Matches of Trie.Substitutions. See Trie.DeletionsSeq.
+func (t *Trie) SubstitutionsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(t, runes(s), distance, Substitutions)
+}
+
+// Stream the Matches of Trie.Transpositions. See Trie.DeletionsSeq.
+func (t *Trie) TranspositionsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(t, runes(s), distance, Transpositions)
+}
+
+// Stream the Matches of Trie.SuggestWords, unranked. See Trie.DeletionsSeq.
+func (t *Trie) SuggestWordsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(t, runes(s), distance, AllEdits)
+}
+
+// See Trie.AllFullChildrenSeq
+func (l lexicon[N]) AllFullChildrenSeq() iter.Seq[string] {
+	return wordSeq(l.root)
+}
+
+// See Trie.DeletionsSeq
+func (l lexicon[N]) DeletionsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(l.root, runes(s), distance, Deletions)
+}
+
+// See Trie.AdditionsSeq
+func (l lexicon[N]) AdditionsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(l.root, runes(s), distance, Additions)
+}
+
+// See Trie.SubstitutionsSeq
+func (l lexicon[N]) SubstitutionsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(l.root, runes(s), distance, Substitutions)
+}
+
+// See Trie.TranspositionsSeq
+func (l lexicon[N]) TranspositionsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(l.root, runes(s), distance, Transpositions)
+}
+
+// See Trie.SuggestWordsSeq
+func (l lexicon[N]) SuggestWordsSeq(s string, distance int) iter.Seq[Match] {
+	return searchSeq(l.root, runes(s), distance, AllEdits)
+}
+
+// Stream the values of seq over the version of a ConcurrentTrie current
+// when the loop starts, so that the loop may change the ConcurrentTrie
+func currentSeq[T any](c *ConcurrentTrie, seq func(*PersistentTrie) iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seq(c.Snapshot())(yield)
+	}
+}
+
+// See Trie.AllFullChildrenSeq. The words are streamed from the version
+// current when the loop starts, so the loop may change the ConcurrentTrie.
+func (c *ConcurrentTrie) AllFullChildrenSeq() iter.Seq[string] {
+	return currentSeq(c, (*PersistentTrie).AllFullChildrenSeq)
+}
+
+// See Trie.DeletionsSeq and ConcurrentTrie.AllFullChildrenSeq
+func (c *ConcurrentTrie) DeletionsSeq(s string, distance int) iter.Seq[Match] {
+	return currentSeq(c, func(p *PersistentTrie) iter.Seq[Match] { return p.DeletionsSeq(s, distance) })
+}
+
+// See Trie.AdditionsSeq and ConcurrentTrie.AllFullChildrenSeq
+func (c *ConcurrentTrie) AdditionsSeq(s string, distance int) iter.Seq[Match] {
+	return currentSeq(c, func(p *PersistentTrie) iter.Seq[Match] { return p.AdditionsSeq(s, distance) })
+}
+
+// See Trie.SubstitutionsSeq and ConcurrentTrie.AllFullChildrenSeq
+func (c *ConcurrentTrie) SubstitutionsSeq(s string, distance int) iter.Seq[Match] {
+	return currentSeq(c, func(p *PersistentTrie) iter.Seq[Match] { return p.SubstitutionsSeq(s, distance) })
+}
+
+// See Trie.TranspositionsSeq and ConcurrentTrie.AllFullChildrenSeq
+func (c *ConcurrentTrie) TranspositionsSeq(s string, distance int) iter.Seq[Match] {
+	return currentSeq(c, func(p *PersistentTrie) iter.Seq[Match] { return p.TranspositionsSeq(s, distance) })
+}
+
+// See Trie.SuggestWordsSeq and ConcurrentTrie.AllFullChildrenSeq
+func (c *ConcurrentTrie) SuggestWordsSeq(s string, distance int) iter.Seq[Match] {
+	return currentSeq(c, func(p *PersistentTrie) iter.Seq[Match] { return p.SuggestWordsSeq(s, distance) })
+}
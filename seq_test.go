@@ -0,0 +1,109 @@
+//go:build go1.23
+
+package gospell
+
+import (
+	"iter"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSeq(t *testing.T) {
+	trie := testTrie()
+	type seqs interface {
+		AllFullChildrenSeq() iter.Seq[string]
+		DeletionsSeq(string, int) iter.Seq[Match]
+		AdditionsSeq(string, int) iter.Seq[Match]
+		SubstitutionsSeq(string, int) iter.Seq[Match]
+		TranspositionsSeq(string, int) iter.Seq[Match]
+		SuggestWordsSeq(string, int) iter.Seq[Match]
+	}
+	layouts := map[string]seqs{
+		"Trie":           trie,
+		"PersistentTrie": trie.Snapshot(),
+		"Radix":          trie.Compact(),
+		"MappedTrie":     mappedTrie(t, trie),
+		"DAWG":           dawgOf(t, trie),
+		"ConcurrentTrie": NewConcurrentTrie(trie),
+	}
+	collect := func(seq iter.Seq[Match]) []string {
+		matches := Matches{}
+		for m := range seq {
+			matches = append(matches, m)
+		}
+		return matches.Strings()
+	}
+	for name, layout := range layouts {
+		words := []string{}
+		for w := range layout.AllFullChildrenSeq() {
+			words = append(words, w)
+		}
+		if !reflect.DeepEqual(words, trie.AllFullChildren()) {
+			t.Errorf("%v: AllFullChildrenSeq streamed %v", name, words)
+		}
+		for _, test := range []struct {
+			seq      iter.Seq[Match]
+			expected []string
+		}{
+			{layout.DeletionsSeq("toad", 1), trie.Deletions("toad", 1)},
+			{layout.AdditionsSeq("toad", 1), trie.Additions("toad", 1)},
+			{layout.SubstitutionsSeq("toad", 1), trie.Substitutions("toad", 1)},
+			{layout.TranspositionsSeq("btoda", 2), trie.Transpositions("btoda", 2)},
+			{layout.SuggestWordsSeq("toad", 2), trie.SuggestWords("toad", 2)},
+		} {
+			if matches := collect(test.seq); !reflect.DeepEqual(matches, test.expected) {
+				t.Errorf("%v: streamed %v, expected %v", name, matches, test.expected)
+			}
+		}
+	}
+
+	// Breaking out of the loop stops the search
+	count := 0
+	for range trie.SuggestWordsSeq("toad", 2) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("Streamed %d matches", count)
+	}
+
+	// The loop streams the version current when it started, and may change
+	// the ConcurrentTrie
+	c := NewConcurrentTrie(testTrie())
+	for m := range c.SuggestWordsSeq("toad", 1) {
+		if string(m.Word) == "toadx" {
+			t.Errorf("'toadx' was inserted after the loop started")
+		}
+		c.InsertString("toadx")
+	}
+	if !c.ContainsString("toadx") {
+		t.Errorf("'toadx' should be inserted")
+	}
+
+	// The loop may use the ConcurrentTrie while a writer runs
+	done := make(chan bool)
+	for m := range c.SuggestWordsSeq("toad", 1) {
+		go func() {
+			c.InsertString("toadstool")
+			done <- true
+		}()
+		// Give the writer time to run
+		time.Sleep(10 * time.Millisecond)
+		if !c.ContainsString(string(m.Word)) {
+			t.Errorf("%q should be Contained", string(m.Word))
+		}
+		<-done
+		break
+	}
+	walked := 0
+	c.Walk(nil, func(word string, _ int) bool {
+		walked++
+		return c.ContainsString(word)
+	})
+	if walked != len(c.AllFullChildren()) {
+		t.Errorf("Walked %d words", walked)
+	}
+}
@@ -8,6 +8,8 @@ package gospell
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"strings"
 )
@@ -17,6 +19,9 @@ type children map[rune]*Trie
 type Trie struct {
 	children children
 	leaf     bool
+	weight   int
+	// The weight of the heaviest word in this Trie, used by Complete
+	heaviest int
 }
 
 // Create a new Trie with no children and leaf=false
@@ -27,42 +32,102 @@ func NewTrie() *Trie {
 	return t
 }
 
-// Load a newline-delimited list of words into a new Trie
-func TrieFromFile(fname string) (t *Trie, err error) {
-	trie := NewTrie()
+// Load a newline-delimited list of words into a new Trie. Lines may end in
+// "\n" or "\r\n", the last line needn't end in a newline, and blank lines and
+// lines starting with '#' are skipped.
+func TrieFromFile(fname string) (*Trie, error) {
 	f, err := os.Open(fname)
 	if err != nil {
-		return trie, fmt.Errorf("Can't find file %v", fname)
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
+	}
+	defer f.Close()
+
+	return wordTrie(f, fname)
+}
+
+// Load a newline-delimited list of words from a file in fsys, such as an
+// embed.FS, into a new Trie. See TrieFromFile.
+func TrieFromFS(fsys fs.FS, name string) (*Trie, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %w", err)
 	}
 	defer f.Close()
 
-	reader := bufio.NewReader(f)
-	word, err := reader.ReadString('\n')
+	return wordTrie(f, name)
+}
+
+// Load a newline-delimited list of words from an io.Reader into a new Trie.
+// See TrieFromFile.
+func TrieFromReader(r io.Reader) (*Trie, error) {
+	return wordTrie(r, "")
+}
 
-	for err == nil {
-		// Don't insert the '\n'
-		w := word[:len(word)-1]
-		trie.InsertString(w)
-		word, err = reader.ReadString('\n')
+func wordTrie(r io.Reader, name string) (*Trie, error) {
+	trie := NewTrie()
+	err := scanLines(r, name, func(line int, text string) error {
+		trie.InsertString(strings.TrimSpace(text))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return trie, nil
 }
 
+// Call fn with the one-based number and text of every line of r that isn't
+// blank or a '#' comment, without its line ending. Read errors are wrapped
+// with the name of the file, if there is one.
+func scanLines(r io.Reader, name string, fn func(line int, text string) error) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		if err := fn(line, text); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if name == "" {
+			return fmt.Errorf("reading dictionary: %w", err)
+		}
+		return fmt.Errorf("reading %v: %w", name, err)
+	}
+	return nil
+}
+
 // Insert a strings.Reader into the Trie
 func (t *Trie) Insert(s *strings.Reader) {
 	rune, _, err := s.ReadRune()
 	if err != nil {
 		// We have reached EOF
-		t.leaf = true
+		if !t.leaf {
+			t.leaf = true
+			t.heaviest = max(t.heaviest, 0)
+		}
 		return
 	}
 
 	child := t.children[rune]
 	if child == nil {
 		child = NewTrie()
-		t.children[rune] = child
+		t.addChild(rune, child)
 	}
 	child.Insert(s)
+	t.heaviest = max(t.heaviest, child.heaviest)
+}
+
+// Add a child, making the children map if this node was read without one
+func (t *Trie) addChild(r rune, child *Trie) {
+	if t.children == nil {
+		t.children = make(children)
+	}
+	t.children[r] = child
 }
 
 // Insert a string into the Trie
@@ -70,6 +135,112 @@ func (t *Trie) InsertString(s string) {
 	t.Insert(strings.NewReader(s))
 }
 
+// Insert a strings.Reader into the Trie with a weight, such as its frequency
+// in a corpus. Heavier words are suggested first among words at the same
+// distance. Inserting a word again replaces its weight.
+func (t *Trie) InsertWeighted(s *strings.Reader, weight int) {
+	rune, _, err := s.ReadRune()
+	if err != nil {
+		// We have reached EOF
+		lowered := t.leaf && weight < t.weight && t.weight == t.heaviest
+		t.leaf = true
+		t.weight = weight
+		if lowered {
+			t.reweigh()
+		} else {
+			t.heaviest = max(t.heaviest, weight)
+		}
+		return
+	}
+
+	child := t.children[rune]
+	if child == nil {
+		child = NewTrie()
+		child.heaviest = weight
+		t.addChild(rune, child)
+	}
+	before := child.heaviest
+	child.InsertWeighted(s, weight)
+	t.childChanged(before, child.heaviest)
+}
+
+// Update the heaviest weight after a child's heaviest weight changed
+func (t *Trie) childChanged(before, after int) {
+	switch {
+	case after > t.heaviest:
+		t.heaviest = after
+	case after < before && before == t.heaviest:
+		t.reweigh()
+	}
+}
+
+// Recompute the heaviest weight from the node and its children
+func (t *Trie) reweigh() {
+	t.heaviest = -infinity
+	if t.leaf {
+		t.heaviest = t.weight
+	}
+	for _, child := range t.children {
+		if child != nil {
+			t.heaviest = max(t.heaviest, child.heaviest)
+		}
+	}
+}
+
+// Insert a string into the Trie with a weight. See Trie.InsertWeighted.
+func (t *Trie) InsertStringWeighted(s string, weight int) {
+	t.InsertWeighted(strings.NewReader(s), weight)
+}
+
+// Remove the word in a strings.Reader from the Trie, pruning the nodes that
+// no longer lead to any word. Returns false if the word wasn't Contained.
+func (t *Trie) Remove(s *strings.Reader) bool {
+	rune, _, err := s.ReadRune()
+	if err != nil {
+		// We have reached EOF
+		if !t.leaf {
+			return false
+		}
+		t.leaf = false
+		t.weight = 0
+		t.reweigh()
+		return true
+	}
+
+	child := t.children[rune]
+	if child == nil {
+		return false
+	}
+	before := child.heaviest
+	if !child.Remove(s) {
+		return false
+	}
+	if !child.leaf && len(child.children) == 0 {
+		delete(t.children, rune)
+		child.heaviest = -infinity
+	}
+	t.childChanged(before, child.heaviest)
+	return true
+}
+
+// Remove a string from the Trie. See Trie.Remove.
+func (t *Trie) RemoveString(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	return t.Remove(strings.NewReader(s))
+}
+
+// Get the weight of a word in the Trie, or 0 if it isn't Contained
+func (t *Trie) Weight(s string) int {
+	child := t.Get(strings.NewReader(s))
+	if child == nil || !child.leaf {
+		return 0
+	}
+	return child.weight
+}
+
 // Get the Trie at the end of a strings.Reader
 func (t *Trie) Get(s *strings.Reader) *Trie {
 	rune, _, err := s.ReadRune()
@@ -105,28 +276,25 @@ func (t *Trie) ContainsString(s string) bool {
 	return t.Contains(strings.NewReader(s))
 }
 
-// Get all of the complete child words under this Trie node
+// Get all of the complete child words under this Trie node, in rune order
 func (t *Trie) AllFullChildren() []string {
-	childStrings := []string{}
+	return allWords(t)
+}
 
-	for r, child := range t.children {
-		if child != nil {
-			if child.leaf {
-				childStrings = append(childStrings, string(r))
-			}
-			for _, ccs := range child.AllFullChildren() {
-				childStrings = append(childStrings, string(r)+ccs)
-			}
-		}
-	}
-	return childStrings
+// Call fn with each complete child word under this Trie node and its weight,
+// ordered by the Collation c, until fn returns false. Returns false if fn
+// did.
+func (t *Trie) Walk(c Collation, fn func(word string, weight int) bool) bool {
+	return walk(t, c, func(word []rune, weight int) bool {
+		return fn(string(word), weight)
+	})
 }
 
-// Convert a Trie to a String
+// Convert a Trie to a String, with children in rune order
 func (t *Trie) String() string {
 	c := ""
-	for r, child := range t.children {
-		c += fmt.Sprintf("%q: %v", r, child)
+	for _, e := range sortedChildren(t, nil) {
+		c += fmt.Sprintf("%q: %v", e.r, e.child)
 	}
 	s := fmt.Sprintf("{leaf: %t, c: %v}", t.leaf, c)
 	return s
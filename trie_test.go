@@ -2,9 +2,15 @@ package gospell
 
 import (
 	"bufio"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"testing/iotest"
 )
 
 func TestInsert(t *testing.T) {
@@ -28,6 +34,82 @@ func TestInsert(t *testing.T) {
 	}
 }
 
+func TestInsertWeighted(t *testing.T) {
+	trie := NewTrie()
+	trie.InsertStringWeighted("the", 100)
+	trie.InsertString("then")
+	trie.InsertWeighted(strings.NewReader("thence"), 3)
+
+	if !trie.ContainsString("the") || !trie.ContainsString("thence") {
+		t.Error("Weighted words should be Contained")
+	}
+	if w := trie.Weight("the"); w != 100 {
+		t.Errorf("Weight of 'the' is %d", w)
+	}
+	if w := trie.Weight("then"); w != 0 {
+		t.Errorf("Weight of 'then' is %d", w)
+	}
+	if w := trie.Weight("th"); w != 0 {
+		t.Errorf("Weight of a prefix is %d", w)
+	}
+
+	trie.InsertStringWeighted("thence", 7)
+	if w := trie.Weight("thence"); w != 7 {
+		t.Errorf("Reinserting should replace the weight, got %d", w)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	s1 := "Salmon"
+	s2 := "Salmonella"
+
+	trie := NewTrie()
+	trie.InsertString(s1)
+	trie.InsertStringWeighted(s2, 5)
+	trie.InsertString("Sal")
+	nodes := trie.nodeCount()
+
+	if trie.RemoveString("Salmo") || trie.RemoveString("Salmonellas") || trie.RemoveString("") {
+		t.Error("Words that aren't Contained can't be removed")
+	}
+	if trie.nodeCount() != nodes {
+		t.Error("Failed removals shouldn't change the Trie")
+	}
+
+	if !trie.Remove(strings.NewReader(s2)) {
+		t.Errorf("%q should have been removed", s2)
+	}
+	if trie.ContainsString(s2) || trie.Weight(s2) != 0 {
+		t.Errorf("%q is still in the Trie", s2)
+	}
+	if trie.Get(strings.NewReader(s1+"e")) != nil {
+		t.Error("Empty branches should be pruned")
+	}
+	if trie.RemoveString(s2) {
+		t.Error("Removing twice should fail")
+	}
+
+	// Removing a prefix of another word keeps the longer word
+	if !trie.RemoveString("Sal") {
+		t.Error("'Sal' should have been removed")
+	}
+	if !HasEveryElement(t, trie.AllFullChildren(), []string{s1}) {
+		t.Error(trie.AllFullChildren())
+	}
+	if n := trie.nodeCount(); n != len(s1)+1 {
+		t.Errorf("Expected %d nodes, found %d", len(s1)+1, n)
+	}
+
+	trie.RemoveString(s1)
+	if len(trie.children) != 0 {
+		t.Error("Removing every word should leave an empty Trie")
+	}
+	trie.InsertString(s2)
+	if !trie.ContainsString(s2) {
+		t.Error("Words can be inserted after removing")
+	}
+}
+
 func HasEveryElement(t *testing.T, s1, s2 []string) bool {
 	s1Map := map[string]int{}
 	for _, val := range s1 {
@@ -64,6 +146,61 @@ func TestAllChildStrings(t *testing.T) {
 	}
 }
 
+func TestTrieFromReader(t *testing.T) {
+	words := "Salmon\nSalmonella\n"
+	trie, err := TrieFromReader(strings.NewReader(words))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !HasEveryElement(t, trie.AllFullChildren(), []string{"Salmon", "Salmonella"}) {
+		t.Error(trie.AllFullChildren())
+	}
+
+	fsys := fstest.MapFS{"dict/words": &fstest.MapFile{Data: []byte(words)}}
+	trie, err = TrieFromFS(fsys, "dict/words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !HasEveryElement(t, trie.AllFullChildren(), []string{"Salmon", "Salmonella"}) {
+		t.Error(trie.AllFullChildren())
+	}
+	if _, err := TrieFromFS(fsys, "dict/missing"); err == nil {
+		t.Error("Loading a missing file should fail")
+	}
+}
+
+func TestTrieFromReaderLines(t *testing.T) {
+	words := "# A comment\r\nSalmon\r\n\r\n  \nsalmon \n#not a word\nSalmonella"
+	trie, err := TrieFromReader(strings.NewReader(words))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Salmon", "salmon", "Salmonella"}
+	if !HasEveryElement(t, trie.AllFullChildren(), expected) {
+		t.Error(trie.AllFullChildren())
+	}
+	if trie.leaf {
+		t.Error("Blank lines shouldn't insert the empty word")
+	}
+}
+
+func TestTrieFromReaderErrors(t *testing.T) {
+	boom := errors.New("boom")
+	r := io.MultiReader(strings.NewReader("Salmon\n"), iotest.ErrReader(boom))
+	if _, err := TrieFromReader(r); !errors.Is(err, boom) {
+		t.Errorf("Read errors should be reported, got %v", err)
+	}
+
+	_, err := TrieFromFile(filepath.Join(t.TempDir(), "missing"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Missing files should be reported, got %v", err)
+	}
+	_, err = TrieFromFS(fstest.MapFS{}, "missing")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Missing files should be reported, got %v", err)
+	}
+}
+
 func BenchmarkLoadDict(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()